@@ -0,0 +1,118 @@
+// Command acpcdealer deals a match to agents speaking the match state protocol of the Annual
+// Computer Poker Competition, taking the same arguments as the competition's dealer:
+//
+//	acpcdealer [flags] <match name> <game definition> <hands> <seed> <name>...
+//
+// A port is opened for each player, and the ports are printed on a single line. Once an agent has
+// connected to every port the match is played, the hands are logged to <match name>.log and the
+// scores printed. Playing the match again with the same seed and the names in another order deals
+// the same cards to each position, for duplicate matches.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"github.com/timothysugar/hand/pkg/acpc"
+)
+
+func main() {
+	ports := flag.String("p", "", "comma separated ports for each player, chosen at random if not given")
+	timeout := flag.Duration("t", 0, "time each agent has to act, or unlimited if 0")
+	fixed := flag.Bool("fixed", false, "keep each player in the same position every hand")
+	flag.Parse()
+	if flag.NArg() < 6 {
+		fmt.Fprintln(os.Stderr, "usage: acpcdealer [flags] <match name> <game definition> <hands> <seed> <name>...")
+		os.Exit(2)
+	}
+	match, names := flag.Arg(0), flag.Args()[4:]
+
+	f, err := os.Open(flag.Arg(1))
+	if err != nil {
+		log.Fatal(err)
+	}
+	game, err := acpc.ParseGame(f)
+	f.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := game.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	hands, err := strconv.Atoi(flag.Arg(2))
+	if err != nil {
+		log.Fatalf("Error parsing hands: %s", err)
+	}
+	seed, err := strconv.ParseInt(flag.Arg(3), 10, 64)
+	if err != nil {
+		log.Fatalf("Error parsing seed: %s", err)
+	}
+	if len(names) != game.Players {
+		log.Fatalf("%d names given for a game of %d players", len(names), game.Players)
+	}
+
+	listeners, err := listen(*ports, len(names))
+	if err != nil {
+		log.Fatal(err)
+	}
+	var addrs []string
+	for _, l := range listeners {
+		addrs = append(addrs, strconv.Itoa(l.Addr().(*net.TCPAddr).Port))
+	}
+	fmt.Println(strings.Join(addrs, " "))
+
+	seats := make([]acpc.Seat, len(names))
+	for i, l := range listeners {
+		conn, err := l.Accept()
+		if err != nil {
+			log.Fatal(err)
+		}
+		l.Close()
+		defer conn.Close()
+		seats[i] = acpc.Seat{Name: names[i], Conn: conn}
+	}
+
+	out, err := os.Create(match + ".log")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer out.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	d := acpc.Dealer{Game: game, Hands: hands, Seed: seed, Timeout: *timeout, FixedPositions: *fixed, Log: out}
+	scores, err := d.Play(ctx, seats)
+	if err != nil {
+		log.Fatalf("Error playing match: %s", err)
+	}
+	for i, v := range scores {
+		fmt.Printf("%s\t%d\n", names[i], v)
+	}
+}
+
+// listen opens a port for each player, on the given ports if any.
+func listen(ports string, players int) ([]net.Listener, error) {
+	ps := make([]string, players)
+	if ports != "" {
+		ps = strings.Split(ports, ",")
+		if len(ps) != players {
+			return nil, fmt.Errorf("%d ports given for %d players", len(ps), players)
+		}
+	}
+	var ls []net.Listener
+	for _, p := range ps {
+		l, err := net.Listen("tcp", ":"+strings.TrimSpace(p))
+		if err != nil {
+			return nil, err
+		}
+		ls = append(ls, l)
+	}
+	return ls, nil
+}
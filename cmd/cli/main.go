@@ -4,9 +4,11 @@ import (
 	"bufio"
 	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log"
+	"math/rand"
 	"os"
 	"os/signal"
 	"strconv"
@@ -16,6 +18,9 @@ import (
 )
 
 func main() {
+	bot := flag.String("bot", "", "strategy playing the second player: random, call or tag")
+	flag.Parse()
+
 	// Setup signal handlers.
 	ctx, cancel := context.WithCancel(context.Background())
 	// ctx, cancel := context.WithTimeout(context.Background(), time.Second)
@@ -34,17 +39,29 @@ func main() {
 		os.Exit(1)
 	}
 	go func() {
-		fin, err = h.Begin()
+		fin, err = h.Begin(ctx)
 		if err != nil {
 			log.Fatalf("Error initializing hand: %s", err)
 		}
 	}()
+	bots := make(map[string]hand.Strategy)
+	if *bot != "" {
+		s, err := parseStrategy(*bot)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		bots[p2.Id] = s
+	}
 	ls := play(os.Stdin, h)
 
 	// Wait for CTRL-C or hand to finish
 out:
 	for {
 		time.Sleep(time.Millisecond * 100) //TODO: use a channel to send valid move updates
+		if err := h.PlayBots(bots); err != nil {
+			fmt.Printf("Bot could not play, %v\n", err)
+		}
 		mvs := h.ValidMoves()
 		fmt.Println("Valid moves: ", mvs)
 		fmt.Printf("Enter an action: [<player index><action><chips>]\ne.g. 0b1⏎ 1f0⏎\n")
@@ -111,11 +128,28 @@ func parseAction(r rune) (hand.Action, error) {
 		return hand.Call, nil
 	case r == 'r':
 		return hand.Raise, nil
+	case r == 'e':
+		return hand.Bet, nil
+	case r == 'a':
+		return hand.AllIn, nil
 	default:
 		return hand.Undefined, errors.New("unsupported action")
 	}
 }
 
+func parseStrategy(name string) (hand.Strategy, error) {
+	switch name {
+	case "random":
+		return hand.NewRandomStrategy(rand.New(rand.NewSource(time.Now().UnixNano()))), nil
+	case "call":
+		return hand.CallingStation{}, nil
+	case "tag":
+		return hand.TightAggressive{}, nil
+	default:
+		return nil, errors.New("unsupported strategy")
+	}
+}
+
 func play(r io.Reader, h *hand.Hand) <-chan string {
 	lines := make(chan string)
 	go func() {
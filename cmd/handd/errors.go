@@ -0,0 +1,34 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/timothysugar/hand/pkg/hand"
+)
+
+// moveErrorStatus returns the HTTP status code for an error returned when playing a move.
+func moveErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, hand.ErrPlayerNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, hand.ErrOutOfTurn), errors.Is(err, hand.ErrHandFinished):
+		return http.StatusConflict
+	case errors.Is(err, hand.ErrInvalidAction), errors.Is(err, hand.ErrInvalidBet):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// seatErrorStatus returns the HTTP status code for an error returned when taking a seat.
+func seatErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, hand.ErrSeatTaken), errors.Is(err, hand.ErrTableFull), errors.Is(err, hand.ErrDuplicatePlayer):
+		return http.StatusConflict
+	case errors.Is(err, hand.ErrInvalidSeat):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusBadRequest
+	}
+}
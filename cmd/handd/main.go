@@ -1,12 +1,18 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-faker/faker/v4"
 	"github.com/gorilla/mux"
@@ -15,17 +21,16 @@ import (
 	"github.com/timothysugar/hand/pkg/hand"
 )
 
-type PlayerViewModel struct {
-	*hand.Player
-	Moves  []hand.Move
-	HandId string
-}
-
 const (
 	initialChips      = 1000
 	initialTableCount = 3
 	tableId           = "table1"
-	playerLimit       = 8
+	tableSeats        = 9
+	actionTimeLimit   = 30 * time.Second
+	timeBank          = 2 * time.Minute
+	// broadcast views reveal hole cards only once the hand has moved on, so cannot be used to ghost
+	broadcastDelayActions = 4
+	broadcastDelay        = time.Minute
 )
 
 type GameState int
@@ -36,64 +41,141 @@ const (
 )
 
 type Table struct {
-	Id      string
-	Name    string
-	players Players
-	Status  GameState
+	Id    string
+	Name  string
+	seats *hand.Seats
+	// dealer deals the hands played at the table, moving the button from one to the next
+	dealer *hand.Table
+	hand   *hand.Hand
+	Status GameState
 }
 
-func NewTable(name string) Table {
-	return Table{
-		Id:      xid.New().String(),
-		Name:    name,
-		players: NewPlayers(playerLimit),
-		Status:  Lobby,
+func NewTable(name string) (*Table, error) {
+	seats, err := hand.NewSeats(tableSeats)
+	if err != nil {
+		return nil, err
 	}
+	return &Table{
+		Id:     xid.New().String(),
+		Name:   name,
+		seats:  seats,
+		Status: Lobby,
+	}, nil
 }
 
-var tables []Table = make([]Table, 0)
-var h *hand.Hand
+// tables and their seats are read and changed by concurrent requests, so are only accessed holding
+// tablesMu
+var tables []*Table = make([]*Table, 0)
+var tablesMu sync.RWMutex
+
+// home is the table at which I play against the bots
+var home *Table
+var cancelHand context.CancelFunc
 var me *hand.Player
+
+// bots play for the players other than me, one move at a time
+var bots map[string]hand.Strategy
+var botsMu sync.Mutex
 var ts *templates.Template
 
 func init() {
 	log.Println("Initializing hand")
 	ts = templates.New()
 	for i := 0; i < initialTableCount; i++ {
-		tables = append(tables, NewTable(fmt.Sprintf("table%d", i)))
+		t, err := NewTable(fmt.Sprintf("table%d", i))
+		if err != nil {
+			log.Fatalf("Error initializing table: %s", err)
+		}
+		tables = append(tables, t)
 	}
 
 	bill := hand.NewPlayer("Bill", initialChips)
 	bill.Cards = []hand.Card{
-		{Suit: "Spades", Rank: "Ace"},
-		{Suit: "Clubs", Rank: "Ace"},
+		{Suit: hand.Spades, Rank: hand.Ace},
+		{Suit: hand.Clubs, Rank: hand.Ace},
 	}
 	ben := hand.NewPlayer("Ben", initialChips)
 	ben.Cards = []hand.Card{
-		{Suit: "Hearts", Rank: "Ace"},
-		{Suit: "Diamonds", Rank: "Ace"},
+		{Suit: hand.Hearts, Rank: hand.Ace},
+		{Suit: hand.Diamonds, Rank: hand.Ace},
 	}
 	me = hand.NewPlayer("Zephyr", initialChips)
 	me.Cards = []hand.Card{
-		{Suit: "Hearts", Rank: "10"},
-		{Suit: "Diamonds", Rank: "Queen"},
+		{Suit: hand.Hearts, Rank: hand.Ten},
+		{Suit: hand.Diamonds, Rank: hand.Queen},
 	}
-	players := []*hand.Player{
-		bill,
-		ben,
-		me,
+	home = tables[0]
+	seats := home.seats
+	for seat, p := range map[int]*hand.Player{1: bill, 4: ben, 7: me} {
+		if err := seats.Sit(seat, p); err != nil {
+			log.Fatalf("Error seating player: %s", err)
+		}
 	}
-	var err error
-	h, err = hand.NewHand(players, players[2], 10)
-	h.Begin()
-	if err != nil {
+	rules := hand.Rules{
+		Blinds: []int{10},
+		TimeLimits: &hand.TimeLimits{
+			PerAction: actionTimeLimit,
+			TimeBank:  timeBank,
+			OnTimeout: func(to hand.Timeout) {
+				log.Printf("Player %s timed out in hand %s so played %v", to.PlayerId, to.HandId, to.Input)
+				// the bots are not otherwise asked to play until I next move
+				playBots()
+			},
+		},
+	}
+	home.dealer = hand.NewTable(seats, rules)
+	bots = map[string]hand.Strategy{bill.Id: hand.TightAggressive{}, ben.Id: hand.CallingStation{}}
+	var ctx context.Context
+	ctx, cancelHand = context.WithCancel(context.Background())
+	if err := dealHand(ctx, home); err != nil {
 		log.Fatalf("Error initializing hand: %s", err)
 	}
 }
 
+// dealHand deals the next hand at the table and plays the moves of the bots. Another hand is dealt
+// whenever one finishes, until the context is done or too few players are left with chips.
+func dealHand(ctx context.Context, t *Table) error {
+	tablesMu.Lock()
+	next, err := t.dealer.NextHand()
+	tablesMu.Unlock()
+	if err != nil {
+		return err
+	}
+	err = next.SetBroadcastDelay(hand.BroadcastDelay{Actions: broadcastDelayActions, Duration: broadcastDelay})
+	if err != nil {
+		return err
+	}
+	fin, err := next.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	tablesMu.Lock()
+	t.hand = next
+	tablesMu.Unlock()
+
+	go func() {
+		if fh := <-fin; fh.Cancelled() {
+			return
+		}
+		if err := dealHand(ctx, t); err != nil {
+			log.Printf("Error dealing next hand at table %s: %v", t.Name, err)
+		}
+	}()
+	playBots()
+	return nil
+}
+
+// Hand returns the hand being played at the table, or nil if none has been dealt.
+func (t *Table) Hand() *hand.Hand {
+	tablesMu.RLock()
+	defer tablesMu.RUnlock()
+
+	return t.hand
+}
+
 const assetsPath = "cmd/handd/static"
 
-func serve() error {
+func serve(ctx context.Context) error {
 	r := mux.NewRouter()
 	assets := http.Dir(assetsPath)
 	r.PathPrefix("/assets/").Handler(http.StripPrefix("/assets", http.FileServer(assets)))
@@ -102,10 +184,22 @@ func serve() error {
 	r.HandleFunc("/", getTablesHandler).Name("get-hands").Methods("GET")
 	r.HandleFunc("/table", newTableHandler).Name(("new-table")).Methods("POST")
 	r.HandleFunc("/table/{tableId}", getHandHandler).Name("get-game").Methods("GET")
+	r.HandleFunc("/table/{tableId}/watch", watchHandHandler).Name("watch-game").Methods("GET")
+	r.HandleFunc("/table/{tableId}/seat", sitHandler).Name("take-seat").Methods("POST")
 
 	port := ":8070"
+	srv := &http.Server{Addr: port, Handler: r}
+	go func() {
+		<-ctx.Done()
+		log.Println("shutting down game server")
+		cancelHand()
+		srv.Shutdown(context.Background())
+	}()
 	fmt.Printf("listening at http://localhost%s\n", port)
-	return http.ListenAndServe(port, r)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
 }
 
 func main() {
@@ -117,7 +211,9 @@ func main() {
 	flag.PrintDefaults()
 	if len(os.Args) < 2 {
 		log.Println("running game server")
-		if err := serve(); err != nil {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		if err := serve(ctx); err != nil {
 			log.Fatalf("unexpected error running game serving so exiting: %s", err)
 		}
 		os.Exit(0)
@@ -132,126 +228,95 @@ func main() {
 	}
 }
 
-var classSuffixLookup = map[hand.Card]string{
-	{Rank: "Ace", Suit: "Clubs"}:      "ac",
-	{Rank: "2", Suit: "Clubs"}:        "2c",
-	{Rank: "3", Suit: "Clubs"}:        "3c",
-	{Rank: "4", Suit: "Clubs"}:        "4c",
-	{Rank: "5", Suit: "Clubs"}:        "5c",
-	{Rank: "6", Suit: "Clubs"}:        "6c",
-	{Rank: "7", Suit: "Clubs"}:        "7c",
-	{Rank: "8", Suit: "Clubs"}:        "8c",
-	{Rank: "9", Suit: "Clubs"}:        "9c",
-	{Rank: "10", Suit: "Clubs"}:       "10c",
-	{Rank: "Jack", Suit: "Clubs"}:     "jc",
-	{Rank: "Queen", Suit: "Clubs"}:    "qc",
-	{Rank: "King", Suit: "Clubs"}:     "kc",
-	{Rank: "Ace", Suit: "Diamonds"}:   "ad",
-	{Rank: "2", Suit: "Diamonds"}:     "2d",
-	{Rank: "3", Suit: "Diamonds"}:     "3d",
-	{Rank: "4", Suit: "Diamonds"}:     "4d",
-	{Rank: "5", Suit: "Diamonds"}:     "5d",
-	{Rank: "6", Suit: "Diamonds"}:     "6d",
-	{Rank: "7", Suit: "Diamonds"}:     "7d",
-	{Rank: "8", Suit: "Diamonds"}:     "8d",
-	{Rank: "9", Suit: "Diamonds"}:     "9d",
-	{Rank: "10", Suit: "Diamonds"}:    "10d",
-	{Rank: "Jack", Suit: "Diamonds"}:  "jd",
-	{Rank: "Queen", Suit: "Diamonds"}: "qd",
-	{Rank: "King", Suit: "Diamonds"}:  "kd",
-	{Rank: "Ace", Suit: "Hearts"}:     "ah",
-	{Rank: "2", Suit: "Hearts"}:       "2h",
-	{Rank: "3", Suit: "Hearts"}:       "3h",
-	{Rank: "4", Suit: "Hearts"}:       "4h",
-	{Rank: "5", Suit: "Hearts"}:       "5h",
-	{Rank: "6", Suit: "Hearts"}:       "6h",
-	{Rank: "7", Suit: "Hearts"}:       "7h",
-	{Rank: "8", Suit: "Hearts"}:       "8h",
-	{Rank: "9", Suit: "Hearts"}:       "9h",
-	{Rank: "10", Suit: "Hearts"}:      "10h",
-	{Rank: "Jack", Suit: "Hearts"}:    "jh",
-	{Rank: "Queen", Suit: "Hearts"}:   "qh",
-	{Rank: "King", Suit: "Hearts"}:    "kh",
-	{Rank: "Ace", Suit: "Spades"}:     "as",
-	{Rank: "2", Suit: "Spades"}:       "2s",
-	{Rank: "3", Suit: "Spades"}:       "3s",
-	{Rank: "4", Suit: "Spades"}:       "4s",
-	{Rank: "5", Suit: "Spades"}:       "5s",
-	{Rank: "6", Suit: "Spades"}:       "6s",
-	{Rank: "7", Suit: "Spades"}:       "7s",
-	{Rank: "8", Suit: "Spades"}:       "8s",
-	{Rank: "9", Suit: "Spades"}:       "9s",
-	{Rank: "10", Suit: "Spades"}:      "10s",
-	{Rank: "Jack", Suit: "Spades"}:    "js",
-	{Rank: "Queen", Suit: "Spades"}:   "qs",
-	{Rank: "King", Suit: "Spades"}:    "ks",
-}
-
+// makeCardClass returns the class of the card's image, which is named by its rank and suit in
+// lower case, with tens numbered.
 func makeCardClass(card hand.Card) string {
-	suffix := classSuffixLookup[card]
-	return fmt.Sprintf("pcard-%s", suffix)
+	if !card.Valid() {
+		return "pcard-back"
+	}
+	rank := strings.ToLower(card.Rank.String())
+	if card.Rank == hand.Ten {
+		rank = "10"
+	}
+	return fmt.Sprintf("pcard-%s%s", rank, card.Suit)
 }
 
-func createPlayerViewModel(self *hand.Player, tableId string, handId string) templates.PlayerViewModel {
-	allMoves := h.ValidMoves()
-	mvs := allMoves[self.Id]
-	cardsVM := make([]struct {
-		Card  hand.Card
-		Class string
-	}, len(self.Cards))
-	for i, c := range self.Cards {
-		cardsVM[i] = struct {
-			Card  hand.Card
-			Class string
-		}{
-			c,
-			makeCardClass(c),
+func createCardsViewModel(cards []hand.Card) []templates.CardViewModel {
+	cardsVM := make([]templates.CardViewModel, len(cards))
+	for i, c := range cards {
+		cardsVM[i] = templates.CardViewModel{
+			Card:  c,
+			Class: makeCardClass(c),
 		}
 	}
+	return cardsVM
+}
+
+func createEntrantViewModel(seat hand.SeatView, button int) templates.EntrantViewModel {
+	return templates.EntrantViewModel{
+		Seat:   seat.Seat,
+		Button: seat.Seat != 0 && seat.Seat == button,
+		Name:   seat.Name,
+		Chips:  seat.Chips,
+		Bet:    seat.Committed,
+		Folded: seat.Folded,
+		Active: seat.NextToPlay,
+	}
+}
+
+func createPlayerViewModel(view hand.View, tableId string, handId string) templates.PlayerViewModel {
 	return templates.PlayerViewModel{
-		Id:      self.Id,
-		TableId: tableId,
-		HandId:  handId,
-		EntrantViewModel: templates.EntrantViewModel{
-			Name:   me.Name,
-			Chips:  me.Chips,
-			Folded: me.Folded,
-			Active: h.IsNextToPlay(self.Id),
-		},
-		Cards: cardsVM,
-		Moves: mvs,
+		Id:               view.Self.Id,
+		TableId:          tableId,
+		HandId:           handId,
+		EntrantViewModel: createEntrantViewModel(view.Self, view.Button),
+		Cards:            createCardsViewModel(view.Self.Cards),
+		Moves:            view.Moves,
 	}
 }
 
-func createHandViewModel(playerId string, tableId string, handId string) templates.HandViewModel {
-	self, opponents := h.Players(playerId)
+func createOpponentsViewModel(opponents []hand.SeatView, button int) []templates.OpponentViewModel {
 	opponentsVM := make([]templates.OpponentViewModel, len(opponents))
 	for i, o := range opponents {
 		opponentsVM[i] = templates.OpponentViewModel{
-			EntrantViewModel: templates.EntrantViewModel{
-				Name:   o.Name,
-				Chips:  o.Chips,
-				Folded: o.Folded,
-				Active: h.IsNextToPlay(o.Id),
-			},
-			FaceDownCards: make([]struct{}, len(o.Cards)),
+			EntrantViewModel: createEntrantViewModel(o, button),
+			Cards:            createCardsViewModel(o.Cards),
+			FaceDownCards:    make([]struct{}, o.FaceDown),
 		}
 	}
-	playerVM := createPlayerViewModel(self, tableId, handId)
+	return opponentsVM
+}
+
+func createHandViewModel(playerId string, tableId string, handId string) (templates.HandViewModel, error) {
+	view, err := home.Hand().ViewFor(playerId)
+	if err != nil {
+		return templates.HandViewModel{}, err
+	}
 
 	return templates.HandViewModel{
-		HandId:    h.Id,
+		HandId:    view.HandId,
 		TableId:   tableId,
-		Opponents: opponentsVM,
-		Player:    playerVM,
-	}
+		Street:    view.Street.String(),
+		Pot:       view.Pot,
+		Opponents: createOpponentsViewModel(view.Opponents, view.Button),
+		Player:    createPlayerViewModel(view, tableId, handId),
+	}, nil
 }
 
 func getTablesHandler(w http.ResponseWriter, req *http.Request) {
-	var links = make([]string, len(tables))
+	tablesMu.RLock()
+	var links = make([]templates.TableViewModel, len(tables))
 	for i, v := range tables {
-		links[i] = fmt.Sprintf("/table/%s/hand/%s", tableId, v.Id)
+		links[i] = templates.TableViewModel{
+			Name:     v.Name,
+			PlayUrl:  fmt.Sprintf("/table/%s/hand/%s", tableId, v.Id),
+			WatchUrl: fmt.Sprintf("/table/%s/watch", v.Id),
+			SitUrl:   fmt.Sprintf("/table/%s/seat", v.Id),
+			Seated:   len(v.seats.Occupied()),
+			Seats:    v.seats.Len(),
+		}
 	}
+	tablesMu.RUnlock()
 
 	name := "hands.go.html"
 	err := ts.Render(w, name, links)
@@ -263,7 +328,63 @@ func getTablesHandler(w http.ResponseWriter, req *http.Request) {
 }
 
 func newTableHandler(w http.ResponseWriter, req *http.Request) {
-	tables = append(tables, NewTable(faker.Name()))
+	t, err := NewTable(faker.Name())
+	if err != nil {
+		log.Printf("Error creating table, err: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	tablesMu.Lock()
+	defer tablesMu.Unlock()
+	tables = append(tables, t)
+}
+
+// sitHandler seats a new player at the table for the next hand, in the chosen seat if there is one
+// or otherwise in any empty seat.
+func sitHandler(w http.ResponseWriter, req *http.Request) {
+	if err := req.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	tableId := mux.Vars(req)["tableId"]
+	t, ok := findTable(tableId)
+	if !ok {
+		log.Printf("Table not found: %s", tableId)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	name := req.PostForm.Get("name")
+	if name == "" {
+		name = faker.FirstName()
+	}
+	p := hand.NewPlayer(name, initialChips)
+
+	var seat int
+	s := req.PostForm.Get("seat")
+	chosen := s != ""
+	if chosen {
+		var err error
+		if seat, err = strconv.Atoi(s); err != nil {
+			log.Printf("Value of 'seat' must be an integer but was not: %s", s)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	var err error
+	tablesMu.Lock()
+	if chosen {
+		err = t.seats.Sit(seat, p)
+	} else {
+		_, err = t.seats.SitAnywhere(p)
+	}
+	tablesMu.Unlock()
+	if err != nil {
+		log.Printf("Error seating %s: %v\n", name, err)
+		http.Error(w, err.Error(), seatErrorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
 }
 
 func getHandHandler(w http.ResponseWriter, req *http.Request) {
@@ -271,10 +392,15 @@ func getHandHandler(w http.ResponseWriter, req *http.Request) {
 	tableId := pathVars["tableId"]
 	handId := pathVars["handId"]
 
-	vm := createHandViewModel(me.Id, tableId, handId)
+	vm, err := createHandViewModel(me.Id, tableId, handId)
+	if err != nil {
+		log.Printf("Error creating view of hand, err: %v", err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
 
 	name := "hand.go.html"
-	err := ts.Render(w, name, vm)
+	err = ts.Render(w, name, vm)
 	if err != nil {
 		log.Printf("Error rendering template, err: %v, template name: %s, data: %v", err, name, vm)
 		w.WriteHeader(http.StatusInternalServerError)
@@ -285,11 +411,41 @@ func getHandHandler(w http.ResponseWriter, req *http.Request) {
 func watchHandHandler(w http.ResponseWriter, req *http.Request) {
 	pathVars := mux.Vars(req)
 	tableId := pathVars["tableId"]
-	handId := pathVars["handId"]
+	t, ok := findTable(tableId)
+	if !ok {
+		log.Printf("Table not found: %s", tableId)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	h := t.Hand()
+	if h == nil {
+		log.Printf("No hand being played at table: %s", tableId)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
 
-	vm := createHandViewModel(me.Id, tableId, handId)
+	var view hand.View
+	delayed := req.URL.Query().Has("broadcast")
+	if delayed {
+		var err error
+		if view, err = h.BroadcastView(); err != nil {
+			log.Printf("Error creating broadcast view of hand, err: %v", err)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+	} else {
+		view = h.SpectatorView()
+	}
+	vm := templates.SpectatorViewModel{
+		HandId:  view.HandId,
+		TableId: tableId,
+		Street:  view.Street.String(),
+		Pot:     view.Pot,
+		Players: createOpponentsViewModel(view.Opponents, view.Button),
+		Delayed: delayed,
+	}
 
-	name := "hand.go.html"
+	name := "spectate.go.html"
 	err := ts.Render(w, name, vm)
 	if err != nil {
 		log.Printf("Error rendering template, err: %v, template name: %s, data: %v", err, name, vm)
@@ -298,6 +454,48 @@ func watchHandHandler(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// playBots plays the moves of the bots until it is my turn, folding any bot which fails to play.
+func playBots() {
+	botsMu.Lock()
+	defer botsMu.Unlock()
+
+	h := home.Hand()
+	for {
+		err := h.PlayBots(bots)
+		if err == nil || errors.Is(err, hand.ErrOutOfTurn) {
+			return
+		}
+		id := h.State().NextToPlay
+		log.Printf("Bot %s could not play so folding: %v", id, err)
+		if err := h.Play(id, hand.Input{Action: hand.Fold}); err != nil {
+			log.Printf("Error folding bot %s: %v", id, err)
+			return
+		}
+	}
+}
+
+// parseAction returns the action with the given name as submitted by the move forms.
+func parseAction(name string) (hand.Action, bool) {
+	for _, a := range []hand.Action{hand.Blind, hand.Check, hand.Fold, hand.Call, hand.Raise, hand.Bet, hand.AllIn} {
+		if a.String() == name {
+			return a, true
+		}
+	}
+	return hand.Undefined, false
+}
+
+func findTable(id string) (*Table, bool) {
+	tablesMu.RLock()
+	defer tablesMu.RUnlock()
+
+	for _, v := range tables {
+		if v.Id == id {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
 func moveHandler(w http.ResponseWriter, req *http.Request) {
 	if err := req.ParseForm(); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
@@ -315,25 +513,35 @@ func moveHandler(w http.ResponseWriter, req *http.Request) {
 	action := req.PostForm.Get("action")
 	bet := req.PostForm.Get("bet")
 	amount, err := strconv.Atoi(bet)
+	if bet == "" {
+		amount, err = 0, nil
+	}
 	if err != nil {
 		log.Printf("Value of 'bet' must be an integer but was not: %s", bet)
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	if action != "Blind" {
+	a, ok := parseAction(action)
+	if !ok {
 		log.Printf("Unsupported action: %s", action)
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	if err := h.PlayBlind(playerId, amount); err != nil {
-		log.Printf("Error playing blind: %v\n", err)
-		w.WriteHeader(http.StatusBadRequest)
+	if err := home.Hand().Play(playerId, hand.Input{Action: a, Chips: amount}); err != nil {
+		log.Printf("Error playing %s: %v\n", action, err)
+		http.Error(w, err.Error(), moveErrorStatus(err))
 		return
 	}
+	playBots()
 
-	vm := createHandViewModel(playerId, tableId, handId)
+	vm, err := createHandViewModel(playerId, tableId, handId)
+	if err != nil {
+		log.Printf("Error creating view of hand, err: %v", err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
 
 	name := "hand.go.html"
 	err = ts.Render(w, name, vm)
@@ -7,19 +7,24 @@ type PlayerViewModel struct {
 	TableId string
 	HandId  string
 	EntrantViewModel
-	Cards []struct {
-		Card  hand.Card
-		Class string
-	}
+	Cards []CardViewModel
 	Moves []hand.Move
 }
 
 type OpponentViewModel struct {
 	EntrantViewModel
+	Cards         []CardViewModel
 	FaceDownCards []struct{}
 }
 
+type CardViewModel struct {
+	Card  hand.Card
+	Class string
+}
+
 type EntrantViewModel struct {
+	Seat   int
+	Button bool
 	Name   string
 	Chips  int
 	Bet    int
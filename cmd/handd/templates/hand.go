@@ -3,6 +3,8 @@ package templates
 type HandViewModel struct {
 	HandId    string
 	TableId   string
+	Street    string
+	Pot       int
 	Opponents []OpponentViewModel
 	Player    PlayerViewModel
 }
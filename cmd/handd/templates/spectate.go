@@ -0,0 +1,19 @@
+package templates
+
+type SpectatorViewModel struct {
+	HandId  string
+	TableId string
+	Street  string
+	Pot     int
+	Players []OpponentViewModel
+	Delayed bool
+}
+
+type TableViewModel struct {
+	Name     string
+	PlayUrl  string
+	WatchUrl string
+	SitUrl   string
+	Seated   int
+	Seats    int
+}
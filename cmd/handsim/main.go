@@ -0,0 +1,94 @@
+// Command handsim plays hands between automated players and reports how each fared, such as
+//
+//	handsim -hands 1000000 -duplicate tag call random
+//
+// Each argument is the strategy of a player: tag, call or random.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/timothysugar/hand/pkg/hand"
+	"github.com/timothysugar/hand/pkg/sim"
+)
+
+func main() {
+	hands := flag.Int("hands", 100000, "number of hands to play")
+	stack := flag.Int("stack", 200, "chips each player starts every hand with")
+	blinds := flag.String("blinds", "1,2", "comma separated blinds")
+	limit := flag.String("limit", "nl", "betting limit: nl, pl or fl")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "seed for shuffling the deck")
+	duplicate := flag.Bool("duplicate", false, "play each deal with the players in every seat")
+	workers := flag.Int("workers", 0, "number of hands played at once, or the number of CPUs if 0")
+	flag.Parse()
+	if flag.NArg() < 2 {
+		fmt.Fprintln(os.Stderr, "at least two strategies are required")
+		os.Exit(2)
+	}
+
+	rules := hand.Rules{PreflopBetting: true}
+	for _, v := range strings.Split(*blinds, ",") {
+		b, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			log.Fatalf("Error parsing blinds: %s", err)
+		}
+		rules.Blinds = append(rules.Blinds, b)
+	}
+	switch *limit {
+	case "nl":
+		rules.Limit = hand.NoLimit
+	case "pl":
+		rules.Limit = hand.PotLimit
+	case "fl":
+		rules.Limit = hand.FixedLimit
+	default:
+		log.Fatalf("Unknown betting limit %q", *limit)
+	}
+
+	var players []sim.Player
+	for i, name := range flag.Args() {
+		d, err := parseDecide(name, *seed+int64(i))
+		if err != nil {
+			log.Fatal(err)
+		}
+		players = append(players, sim.Player{Name: fmt.Sprintf("%d:%s", i+1, name), Decide: d})
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	cfg := sim.Config{Rules: rules, Stack: *stack, Hands: *hands, Seed: *seed, Duplicate: *duplicate, Workers: *workers}
+	report, err := sim.Run(ctx, players, cfg)
+	if err != nil && ctx.Err() == nil {
+		log.Fatalf("Error running simulation: %s", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "player\thands\tchips\tbb/100\t95% CI\tinvalid\t")
+	for _, r := range report.Results {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.2f\t±%.2f\t%d\t\n", r.Name, r.Hands, r.Chips, r.BBPer100, r.CI95, r.Invalid)
+	}
+	w.Flush()
+	fmt.Printf("%d hands in %v (%.0f hands/s)\n", report.Hands, report.Duration.Round(time.Millisecond), report.HandsPerSecond())
+}
+
+// parseDecide returns the decision of the named strategy.
+func parseDecide(name string, seed int64) (sim.Decide, error) {
+	switch name {
+	case "tag":
+		return hand.TightAggressive{}.Decide, nil
+	case "call":
+		return hand.CallingStation{}.Decide, nil
+	case "random":
+		return sim.Random(seed), nil
+	}
+	return nil, fmt.Errorf("unknown strategy %q: expected tag, call or random", name)
+}
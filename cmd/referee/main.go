@@ -0,0 +1,120 @@
+// Command referee plays a match between bots which speak the protocol of package bot, such as
+//
+//	referee -hands 100 "python3 tight.py" "python3 loose.py"
+//
+// Each argument is the command line of a bot. The button moves every hand and the match ends
+// after the given number of hands or once a single bot has chips.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/timothysugar/hand/pkg/bot"
+	"github.com/timothysugar/hand/pkg/hand"
+)
+
+func main() {
+	hands := flag.Int("hands", 100, "number of hands to play")
+	chips := flag.Int("chips", 200, "chips each bot starts with")
+	blinds := flag.String("blinds", "1,2", "comma separated blinds")
+	deadline := flag.Duration("deadline", bot.DefaultDeadline, "time each bot has to act")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "seed for shuffling the deck")
+	flag.Parse()
+	if flag.NArg() < 2 {
+		fmt.Fprintln(os.Stderr, "at least two bot commands are required")
+		os.Exit(2)
+	}
+	bs, err := parseBlinds(*blinds)
+	if err != nil {
+		log.Fatalf("Error parsing blinds: %s", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var players []*hand.Player
+	bots := make(map[string]hand.Strategy)
+	for _, v := range flag.Args() {
+		args := strings.Fields(v)
+		b, err := bot.Start(ctx, *deadline, args[0], args[1:]...)
+		if err != nil {
+			log.Fatalf("Error starting bot %q: %s", v, err)
+		}
+		defer b.Close()
+		p := hand.NewPlayer(b.Name, *chips)
+		players = append(players, p)
+		bots[p.Id] = b
+	}
+
+	rules := matchRules(bs, *seed)
+	for i := 0; i < *hands && ctx.Err() == nil; i++ {
+		var in []*hand.Player
+		for _, p := range players {
+			if p.Chips > 0 {
+				in = append(in, p)
+			}
+		}
+		if len(in) < 2 {
+			break
+		}
+		if err := playHand(ctx, in, in[i%len(in)], rules, bots); err != nil {
+			log.Fatalf("Error playing hand %d: %s", i, err)
+		}
+	}
+
+	for _, p := range players {
+		fmt.Printf("%s\t%d\n", p.Name, p.Chips)
+	}
+}
+
+// matchRules returns the rules of the hands of a match, which are bet before the flop as well as
+// on every street after it.
+func matchRules(blinds []int, seed int64) hand.Rules {
+	return hand.Rules{Blinds: blinds, PreflopBetting: true, Rand: rand.New(rand.NewSource(seed))}
+}
+
+// playHand plays a hand between the bots, whose players are credited with the chips they win.
+func playHand(ctx context.Context, ps []*hand.Player, dealer *hand.Player, rules hand.Rules, bots map[string]hand.Strategy) error {
+	h, err := hand.NewHandWithRules(ps, dealer, rules)
+	if err != nil {
+		return err
+	}
+	fin, err := h.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	for {
+		if err := h.PlayBots(bots); err != nil {
+			// the bot was folded after an invalid reply, so the engine rejecting its move is
+			// a fault in the harness
+			return err
+		}
+		select {
+		case <-fin:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func parseBlinds(s string) ([]int, error) {
+	var bs []int
+	for _, v := range strings.Split(s, ",") {
+		b, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return nil, err
+		}
+		bs = append(bs, b)
+	}
+	return bs, nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/timothysugar/hand/pkg/hand"
+)
+
+// recordingBot calls every bet and records the streets on which it was asked to bet, other than to
+// post a blind.
+type recordingBot struct {
+	streets *[]hand.Street
+}
+
+func (b recordingBot) Decide(v hand.View, moves []hand.Move) hand.Input {
+	if len(moves) > 1 || moves[0].Action != hand.Blind {
+		*b.streets = append(*b.streets, v.Street)
+	}
+	return hand.CallingStation{}.Decide(v, moves)
+}
+
+func TestFirstBettingRequestIsSentPreflop(t *testing.T) {
+	p1 := hand.NewPlayer("one", 200)
+	p2 := hand.NewPlayer("two", 200)
+	var streets []hand.Street
+	bots := map[string]hand.Strategy{p1.Id: recordingBot{&streets}, p2.Id: recordingBot{&streets}}
+
+	if err := playHand(context.Background(), []*hand.Player{p1, p2}, p1, matchRules([]int{1, 2}, 1), bots); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(streets) == 0 || streets[0] != hand.Preflop {
+		t.Errorf("expected the first betting request to be sent preflop but was sent on %v", streets)
+	}
+}
@@ -0,0 +1,247 @@
+package acpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timothysugar/hand/pkg/hand"
+)
+
+const headsUpNoLimit = `# 2-player no-limit Texas Hold'em
+GAMEDEF
+nolimit
+numPlayers = 2
+numRounds = 4
+stack = 20000 20000
+blind = 100 50
+firstPlayer = 2 1 1 1
+numSuits = 4
+numRanks = 13
+numHoleCards = 2
+numBoardCards = 0 3 1 1
+END GAMEDEF
+`
+
+const threePlayerLimit = `GAMEDEF
+limit
+numPlayers = 3
+numRounds = 4
+blind = 5 10 0
+raiseSize = 10 10 20 20
+firstPlayer = 3 1 1 1
+maxRaises = 3 4 4 4
+numSuits = 4
+numRanks = 13
+numHoleCards = 2
+numBoardCards = 0 3 1 1
+END GAMEDEF
+`
+
+func parseGame(t *testing.T, def string) Game {
+	t.Helper()
+	g, err := ParseGame(strings.NewReader(def))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+// agent plays as an agent over the connection, replying with the action returned by decide for
+// each state. No reply is sent when it returns an empty action. The states received are sent to
+// the channel once the dealer closes the connection.
+func runAgent(conn net.Conn, decide func(position int, betting string, state string) string) chan []string {
+	states := make(chan []string, 1)
+	go func() {
+		var received []string
+		fmt.Fprint(conn, "VERSION:2.0.0\r\n")
+		s := bufio.NewScanner(conn)
+		for s.Scan() {
+			l := strings.TrimRight(s.Text(), "\r")
+			received = append(received, l)
+			fields := strings.Split(l, ":")
+			var position int
+			fmt.Sscan(fields[1], &position)
+			if action := decide(position, fields[3], l); action != "" {
+				fmt.Fprintf(conn, "%s:%s\r\n", l, action)
+			}
+		}
+		states <- received
+	}()
+	return states
+}
+
+// script returns a decision function replying with the action given for the betting seen by the
+// position, such as "1:r300".
+func script(actions map[string]string) func(int, string, string) string {
+	return func(position int, betting string, _ string) string {
+		return actions[fmt.Sprintf("%d:%s", position, betting)]
+	}
+}
+
+// callingStation checks or calls whenever it is the agent's turn, knowing every other agent does
+// the same and so every hand reaches a showdown.
+func callingStation(g Game) func(int, string, string) string {
+	return func(position int, betting string, state string) string {
+		hole := strings.SplitN(strings.Split(state, ":")[4], "/", 2)[0]
+		if !strings.Contains("|"+hole+"|", "||") {
+			// every player's cards are shown so the hand is over
+			return ""
+		}
+		rounds := strings.Split(betting, "/")
+		r := len(rounds) - 1
+		if (g.FirstPlayer[r]+len(rounds[r]))%g.Players == position {
+			return "c"
+		}
+		return ""
+	}
+}
+
+// play plays a match between agents making the given decisions over pipes, returning the scores
+// and the states received by each agent.
+func play(t *testing.T, d Dealer, names []string, decisions ...func(int, string, string) string) ([]int, [][]string) {
+	t.Helper()
+	var seats []Seat
+	var received []chan []string
+	for i, decide := range decisions {
+		dealer, agent := net.Pipe()
+		defer dealer.Close()
+		seats = append(seats, Seat{Name: names[i], Conn: dealer})
+		received = append(received, runAgent(agent, decide))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	scores, err := d.Play(ctx, seats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var states [][]string
+	for i, s := range seats {
+		s.Conn.(net.Conn).Close()
+		states = append(states, <-received[i])
+	}
+	return scores, states
+}
+
+func TestParseGameReadsDefinitions(t *testing.T) {
+	hu := parseGame(t, headsUpNoLimit)
+	if hu.Limit != hand.NoLimit || hu.Players != 2 || hu.Stacks[1] != 20000 || hu.FirstPlayer[0] != 1 {
+		t.Errorf("expected heads up no limit game but got %+v", hu)
+	}
+	if err := hu.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	limit := parseGame(t, threePlayerLimit)
+	if limit.Limit != hand.FixedLimit || limit.MaxRaises[0] != 3 || limit.Stacks[2] <= 0 {
+		t.Errorf("expected three player limit game but got %+v", limit)
+	}
+	if err := limit.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestParseGameRejectsInvalidAndUnsupportedGames(t *testing.T) {
+	if _, err := ParseGame(strings.NewReader("GAMEDEF\nnumPlayers = 2\nstack = 100\n")); !errors.Is(err, ErrInvalidGame) {
+		t.Errorf("expected %v but got %v", ErrInvalidGame, err)
+	}
+
+	unsupported := map[string]string{
+		"first player":  strings.Replace(headsUpNoLimit, "firstPlayer = 2 1 1 1", "firstPlayer = 1 1 1 1", 1),
+		"board":         strings.Replace(headsUpNoLimit, "numBoardCards = 0 3 1 1", "numBoardCards = 0 3 2 0", 1),
+		"raise size":    strings.Replace(threePlayerLimit, "raiseSize = 10 10 20 20", "raiseSize = 10 20 20 20", 1),
+		"missing blind": strings.Replace(threePlayerLimit, "blind = 5 10 0", "blind = 5 0 10", 1),
+	}
+	for name, def := range unsupported {
+		if err := parseGame(t, def).Validate(); !errors.Is(err, ErrUnsupportedGame) {
+			t.Errorf("expected %s to be %v but got %v", name, ErrUnsupportedGame, err)
+		}
+	}
+}
+
+func TestDealerPlaysHandAndFixesInvalidActions(t *testing.T) {
+	var errs bytes.Buffer
+	var logged bytes.Buffer
+	d := Dealer{Game: parseGame(t, headsUpNoLimit), Hands: 1, Seed: 1, FixedPositions: true, Log: &logged, Errors: log.New(&errs, "", 0)}
+
+	scores, states := play(t, d, []string{"alice", "bob"},
+		script(map[string]string{"0:r200": "c", "0:r200c/": "c", "0:r200c/cc/": "r600"}),
+		// the raise is below the minimum and the fold is checked
+		script(map[string]string{"1:": "r10", "1:r200c/c": "f", "1:r200c/cc/r600": "f"}),
+	)
+
+	if scores[0] != 200 || scores[1] != -200 {
+		t.Errorf("expected alice to win the big blind's raise but got %v", scores)
+	}
+	if !regexp.MustCompile(`^MATCHSTATE:0:0::[2-9TJQKA][cdhs][2-9TJQKA][cdhs]\|$`).MatchString(states[0][0]) {
+		t.Errorf("expected the first state to show only alice's cards but got %q", states[0][0])
+	}
+	last := states[1][len(states[1])-1]
+	if !regexp.MustCompile(`^MATCHSTATE:1:0:r200c/cc/r600f:\|[^|/]{4}/[^/]{6}/[^/]{2}$`).MatchString(last) {
+		t.Errorf("expected the final state of the folded hand but got %q", last)
+	}
+	if !strings.HasPrefix(logged.String(), "STATE:0:r200c/cc/r600f:") || !strings.Contains(logged.String(), ":200|-200:alice|bob\n") {
+		t.Errorf("expected log of the hand but got %q", logged.String())
+	}
+	if !strings.Contains(logged.String(), "SCORE:200|-200:alice|bob") {
+		t.Errorf("expected score in log but got %q", logged.String())
+	}
+	if strings.Count(errs.String(), "not valid") != 2 {
+		t.Errorf("expected the raise and fold to be logged as invalid but got %q", errs.String())
+	}
+}
+
+func TestDealerRotatesPositionsAndDealsDuplicateMatches(t *testing.T) {
+	g := parseGame(t, threePlayerLimit)
+	names := []string{"a", "b", "c"}
+	var first, second bytes.Buffer
+
+	play(t, Dealer{Game: g, Hands: 3, Seed: 9, Log: &first}, names, callingStation(g), callingStation(g), callingStation(g))
+	play(t, Dealer{Game: g, Hands: 3, Seed: 9, Log: &second}, []string{"c", "a", "b"}, callingStation(g), callingStation(g), callingStation(g))
+
+	hands1 := strings.Split(strings.TrimSpace(first.String()), "\n")
+	hands2 := strings.Split(strings.TrimSpace(second.String()), "\n")
+	if len(hands1) != 4 || len(hands2) != 4 {
+		t.Fatalf("expected 3 hands and a score but got\n%s", first.String())
+	}
+	for i := 0; i < 3; i++ {
+		f1, f2 := strings.Split(hands1[i], ":"), strings.Split(hands2[i], ":")
+		if f1[2] != "ccc/ccc/ccc/ccc" {
+			t.Errorf("expected every player to check or call to a showdown but got %q", f1[2])
+		}
+		if f1[3] != f2[3] || f1[4] != f2[4] {
+			t.Errorf("expected hand %d to deal the same cards to each position but got %q and %q", i, hands1[i], hands2[i])
+		}
+	}
+	if !strings.HasSuffix(hands1[0], ":a|b|c") || !strings.HasSuffix(hands1[1], ":b|c|a") {
+		t.Errorf("expected positions to move round the seats but got\n%s", first.String())
+	}
+}
+
+func TestDealerCallsRaisesAboveTheLimit(t *testing.T) {
+	d := Dealer{Game: parseGame(t, threePlayerLimit)}
+	v := hand.View{
+		Self: hand.SeatView{Chips: 1000, Committed: 10},
+		Moves: []hand.Move{
+			hand.NewMove(hand.Fold, hand.NewExactBet(0)),
+			hand.NewMove(hand.Call, hand.NewExactBet(30)),
+			hand.NewMove(hand.Raise, hand.NewBetRange(50, 50)),
+		},
+	}
+
+	if inp, played := d.input("r", v, hand.State{CurrentBet: 40}, 1010, 2); inp.Action != hand.Raise || inp.Chips != 50 || played != "r" {
+		t.Errorf("expected raise to 50 but got %v as %q", inp, played)
+	}
+	if inp, played := d.input("r", v, hand.State{CurrentBet: 40}, 1010, 3); inp.Action != hand.Call || played != "c" {
+		t.Errorf("expected call once raises are capped but got %v as %q", inp, played)
+	}
+}
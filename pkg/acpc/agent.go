@@ -0,0 +1,80 @@
+package acpc
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errTimeout = errors.New("agent did not act in time")
+
+// agent is the connection to an agent in a match.
+type agent struct {
+	name    string
+	w       io.Writer
+	lines   chan string
+	timeout time.Duration
+}
+
+// connect reads the protocol version sent by the agent in the seat.
+func connect(s Seat, timeout time.Duration) (*agent, error) {
+	a := &agent{name: s.Name, w: s.Conn, lines: make(chan string, 16), timeout: timeout}
+	go a.read(s.Conn)
+	l, ok := <-a.lines
+	if !ok {
+		return nil, ErrDisconnected
+	}
+	version, found := strings.CutPrefix(l, "VERSION:")
+	major, _, _ := strings.Cut(version, ".")
+	if v, err := strconv.Atoi(major); !found || err != nil || v != ProtocolVersion {
+		return nil, fmt.Errorf("%w: expected version %d but got %q", ErrProtocol, ProtocolVersion, l)
+	}
+	return a, nil
+}
+
+func (a *agent) read(r io.Reader) {
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		l := strings.TrimRight(s.Text(), "\r")
+		// comments may be sent by agents
+		if l == "" || l[0] == '#' || l[0] == ';' {
+			continue
+		}
+		a.lines <- l
+	}
+	close(a.lines)
+}
+
+func (a *agent) send(state string) error {
+	_, err := io.WriteString(a.w, state+"\r\n")
+	return err
+}
+
+// reply waits for the agent to reply to the state with an action, ignoring replies to any other
+// state.
+func (a *agent) reply(ctx context.Context, state string) (string, error) {
+	var timeout <-chan time.Time
+	if a.timeout > 0 {
+		timeout = time.After(a.timeout)
+	}
+	for {
+		select {
+		case l, ok := <-a.lines:
+			if !ok {
+				return "", ErrDisconnected
+			}
+			if action, found := strings.CutPrefix(l, state+":"); found {
+				return action, nil
+			}
+		case <-timeout:
+			return "", errTimeout
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package acpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/timothysugar/hand/pkg/hand"
+)
+
+// ProtocolVersion is the major version of the protocol agents must speak.
+const ProtocolVersion = 2
+
+var (
+	// ErrProtocol is returned when an agent does not speak the protocol.
+	ErrProtocol = errors.New("protocol error")
+	// ErrDisconnected is returned when an agent disconnects during a match.
+	ErrDisconnected = errors.New("agent disconnected")
+)
+
+// Seat is an agent in a match.
+type Seat struct {
+	Name string
+	Conn io.ReadWriter
+}
+
+// Dealer deals a match of a game to agents. Each hand is dealt from a deck shuffled by a source
+// seeded from the match's seed, so a match played again with the same seed and the seats in a
+// different order deals the same cards to each position, as in a duplicate match.
+//
+// Actions which are not valid are changed to the nearest valid action, as by the competition's
+// dealer: folding when able to check is a call, a raise when none is allowed is a call, and the
+// size of a raise is moved into the allowed range. An agent which does not reply within the
+// timeout folds, or checks if it can.
+type Dealer struct {
+	Game  Game
+	Hands int
+	Seed  int64
+	// Timeout is the time an agent has to act, or unlimited if 0.
+	Timeout time.Duration
+	// FixedPositions keeps each seat in the same position, otherwise the positions move round
+	// the seats every hand.
+	FixedPositions bool
+	// Log receives the result of each hand and the scores of the match, in the format of the
+	// competition's logs, if it is not nil.
+	Log io.Writer
+	// Errors records invalid and late actions. The standard logger is used if it is nil.
+	Errors *log.Logger
+}
+
+// Play plays the match between the agents, which must each first send their protocol version,
+// and returns the chips won by each seat.
+func (d Dealer) Play(ctx context.Context, seats []Seat) ([]int, error) {
+	if err := d.Game.Validate(); err != nil {
+		return nil, err
+	}
+	if len(seats) != d.Game.Players {
+		return nil, fmt.Errorf("%d seats cannot play a game of %d players", len(seats), d.Game.Players)
+	}
+	agents := make([]*agent, len(seats))
+	for i, s := range seats {
+		a, err := connect(s, d.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("seat %d: %w", i, err)
+		}
+		agents[i] = a
+	}
+
+	scores := make([]int, len(seats))
+	r := rand.New(rand.NewSource(d.Seed))
+	for n := 0; n < d.Hands; n++ {
+		// the deck of each hand is independent of the play of those before it
+		seed := r.Int63()
+		positions := make([]*agent, len(agents))
+		for i := range positions {
+			positions[i] = agents[d.seat(i, n)]
+		}
+		values, err := d.playHand(ctx, n, positions, seed)
+		if err != nil {
+			return nil, fmt.Errorf("hand %d: %w", n, err)
+		}
+		for i, v := range values {
+			scores[d.seat(i, n)] += v
+		}
+	}
+	if d.Log != nil {
+		names := make([]string, len(seats))
+		for i, s := range seats {
+			names[i] = s.Name
+		}
+		fmt.Fprintf(d.Log, "SCORE:%s:%s\n", join(scores), strings.Join(names, "|"))
+	}
+	return scores, nil
+}
+
+// seat returns the seat of the agent playing the position in the given hand.
+func (d Dealer) seat(position int, handNumber int) int {
+	if d.FixedPositions {
+		return position
+	}
+	return (position + handNumber) % d.Game.Players
+}
+
+// playHand plays a hand between the agents in each position and returns the chips they won.
+func (d Dealer) playHand(ctx context.Context, number int, positions []*agent, seed int64) ([]int, error) {
+	order, _, _ := d.Game.order()
+	players := make([]*hand.Player, len(positions))
+	position := make(map[string]int, len(positions))
+	for i, a := range positions {
+		players[i] = hand.NewPlayer(a.name, d.Game.Stacks[i])
+		position[players[i].Id] = i
+	}
+	dealt := make([]*hand.Player, len(order))
+	for i, pos := range order {
+		dealt[i] = players[pos]
+	}
+	rules := d.Game.rules()
+	rules.Rand = rand.New(rand.NewSource(seed))
+	h, err := hand.NewHandWithRules(dealt, dealt[0], rules)
+	if err != nil {
+		return nil, err
+	}
+	fin, err := h.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &matchState{hand: number, betting: []string{""}}
+	for _, p := range players {
+		s.hole = append(s.hole, p.Cards)
+	}
+	started := false
+	for {
+		id := h.State().NextToPlay
+		if id == "" {
+			break
+		}
+		v, err := h.ViewFor(id)
+		if err != nil {
+			return nil, err
+		}
+		if m, ok := findMove(v.Moves, hand.Blind); ok {
+			if err := h.Play(id, hand.Input{Action: hand.Blind, Chips: m.Bet.Minimum}); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !started {
+			if err := s.send(positions); err != nil {
+				return nil, err
+			}
+			started = true
+		}
+		pos := position[id]
+		a := positions[pos]
+		reply, err := a.reply(ctx, s.String(pos))
+		late := errors.Is(err, errTimeout)
+		if err != nil && !late {
+			return nil, err
+		}
+		if late {
+			d.logf("%s did not act within %v", a.name, d.Timeout)
+			reply = "f"
+		}
+		inp, played := d.input(reply, v, h.State(), d.Game.Stacks[pos], s.raises())
+		if played != reply && !late {
+			d.logf("%s played %q which is not valid so played %q", a.name, reply, played)
+		}
+		if err := h.Play(id, inp); err != nil {
+			return nil, err
+		}
+		state := h.State()
+		s.play(played, state.Board)
+		if state.NextToPlay != "" {
+			if err := s.send(positions); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	select {
+	case <-fin:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	active := 0
+	for _, p := range players {
+		if !p.Folded {
+			active++
+		}
+	}
+	values := make([]int, len(players))
+	for i, p := range players {
+		values[i] = p.Chips - d.Game.Stacks[i]
+	}
+	// the cards of the players remaining at a showdown are revealed to all
+	for _, p := range players {
+		s.shown = append(s.shown, active > 1 && !p.Folded)
+	}
+	if err := s.send(positions); err != nil {
+		return nil, err
+	}
+	if d.Log != nil {
+		names := make([]string, len(positions))
+		for i, a := range positions {
+			names[i] = a.name
+		}
+		fmt.Fprintf(d.Log, "STATE:%d:%s:%s:%s:%s\n", number, s.bettingString(), s.cards(-1), join(values), strings.Join(names, "|"))
+	}
+	return values, nil
+}
+
+// input returns the input for the action replied by an agent with its view of the hand, and the
+// action actually played in the protocol's notation, after changing an invalid action to the
+// nearest valid one.
+func (d Dealer) input(reply string, v hand.View, state hand.State, stack int, raises int) (hand.Input, string) {
+	// raises are to the total spent in the hand, including on earlier rounds
+	spent := stack - v.Self.Chips
+	earlier := spent - v.Self.Committed
+	call := func() (hand.Input, string) {
+		if _, ok := findMove(v.Moves, hand.Check); ok {
+			return hand.Input{Action: hand.Check}, "c"
+		}
+		if m, ok := findMove(v.Moves, hand.Call); ok {
+			return hand.Input{Action: hand.Call, Chips: m.Bet.Minimum}, "c"
+		}
+		m, _ := findMove(v.Moves, hand.AllIn)
+		return hand.Input{Action: hand.AllIn, Chips: m.Bet.Minimum}, "c"
+	}
+
+	switch {
+	case reply == "f":
+		if _, ok := findMove(v.Moves, hand.Check); ok {
+			return call()
+		}
+		return hand.Input{Action: hand.Fold}, "f"
+	case strings.HasPrefix(reply, "r"):
+		if raises >= d.Game.MaxRaises[round(len(state.Board))] {
+			return call()
+		}
+		for _, a := range []hand.Action{hand.Raise, hand.Bet} {
+			m, ok := findMove(v.Moves, a)
+			if !ok {
+				continue
+			}
+			to := m.Bet.Minimum
+			if a == hand.Bet {
+				to += v.Self.Committed
+			}
+			if size, err := strconv.Atoi(reply[1:]); err == nil && d.Game.Limit == hand.NoLimit {
+				to = size - earlier
+			}
+			lo, hi := m.Bet.Minimum, m.Bet.Maximum
+			if a == hand.Bet {
+				lo, hi = lo+v.Self.Committed, hi+v.Self.Committed
+			}
+			to = clamp(to, lo, hi)
+			chips := to
+			if a == hand.Bet {
+				chips = to - v.Self.Committed
+			}
+			return hand.Input{Action: a, Chips: chips}, d.raise(earlier + to)
+		}
+		if m, ok := findMove(v.Moves, hand.AllIn); ok && v.Self.Committed+m.Bet.Minimum > state.CurrentBet {
+			return hand.Input{Action: hand.AllIn, Chips: m.Bet.Minimum}, d.raise(spent + m.Bet.Minimum)
+		}
+		return call()
+	default:
+		inp, played := call()
+		if reply != "c" {
+			// anything other than a call is not an action, and is played as a fold
+			if _, ok := findMove(v.Moves, hand.Check); !ok {
+				return hand.Input{Action: hand.Fold}, "f"
+			}
+		}
+		return inp, played
+	}
+}
+
+// raise returns a raise to the total spent in the hand in the protocol's notation.
+func (d Dealer) raise(total int) string {
+	if d.Game.Limit == hand.FixedLimit {
+		return "r"
+	}
+	return "r" + strconv.Itoa(total)
+}
+
+func (d Dealer) logf(format string, a ...any) {
+	logger := d.Errors
+	if logger == nil {
+		logger = log.Default()
+	}
+	logger.Printf(format, a...)
+}
+
+func findMove(moves []hand.Move, a hand.Action) (hand.Move, bool) {
+	for _, m := range moves {
+		if m.Action == a {
+			return m, true
+		}
+	}
+	return hand.Move{}, false
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -0,0 +1,293 @@
+// Package acpc deals matches to agents speaking the match state protocol of the Annual Computer
+// Poker Competition, enforcing the rules of each hand with package hand.
+//
+// A match is described by a game definition in the competition's format, such as
+//
+//	GAMEDEF
+//	nolimit
+//	numPlayers = 2
+//	numRounds = 4
+//	stack = 20000 20000
+//	blind = 100 50
+//	firstPlayer = 2 1 1 1
+//	numSuits = 4
+//	numRanks = 13
+//	numHoleCards = 2
+//	numBoardCards = 0 3 1 1
+//	END GAMEDEF
+//
+// Limit and no limit Texas Hold'em definitions are supported for any number of players, as long
+// as the blinds and the order of play are those of a standard game.
+package acpc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/timothysugar/hand/pkg/hand"
+)
+
+var (
+	// ErrInvalidGame is returned when a game definition cannot be parsed.
+	ErrInvalidGame = errors.New("invalid game definition")
+	// ErrUnsupportedGame is returned when a game definition describes a game which cannot be
+	// dealt by package hand.
+	ErrUnsupportedGame = errors.New("unsupported game")
+)
+
+// Game is a game definition. Positions are numbered from 0, the first to post a blind after the
+// flop in a standard game of more than two players.
+type Game struct {
+	Limit   hand.BettingLimit
+	Players int
+	Rounds  int
+	// Stacks are the chips each position starts every hand with.
+	Stacks []int
+	// Blinds are the blinds posted by each position, which may be 0.
+	Blinds []int
+	// RaiseSizes are the size of bets and raises on each round of a limit game.
+	RaiseSizes []int
+	// FirstPlayer is the position which acts first on each round.
+	FirstPlayer []int
+	// MaxRaises is the number of bets and raises allowed on each round.
+	MaxRaises  []int
+	Suits      int
+	Ranks      int
+	HoleCards  int
+	BoardCards []int
+}
+
+// ParseGame reads a game definition. Lines starting with '#' are comments, and values not given
+// take the defaults of the competition's dealer, which has stacks and raises without limit.
+func ParseGame(r io.Reader) (Game, error) {
+	var g Game
+	s := bufio.NewScanner(r)
+	started := false
+	for s.Scan() {
+		l := strings.TrimSpace(s.Text())
+		if l == "" || l[0] == '#' {
+			continue
+		}
+		if !started {
+			if !strings.EqualFold(l, "GAMEDEF") {
+				return Game{}, fmt.Errorf("%w: expected GAMEDEF but got %q", ErrInvalidGame, l)
+			}
+			started = true
+			continue
+		}
+		key, value, _ := strings.Cut(l, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		nums, err := parseInts(value)
+		if err != nil {
+			return Game{}, fmt.Errorf("%w: %s: %v", ErrInvalidGame, key, err)
+		}
+		switch key {
+		case "end gamedef":
+			return g, g.defaults()
+		case "limit":
+			g.Limit = hand.FixedLimit
+		case "nolimit":
+			g.Limit = hand.NoLimit
+		case "numplayers":
+			err = single(nums, &g.Players)
+		case "numrounds":
+			err = single(nums, &g.Rounds)
+		case "numsuits":
+			err = single(nums, &g.Suits)
+		case "numranks":
+			err = single(nums, &g.Ranks)
+		case "numholecards":
+			err = single(nums, &g.HoleCards)
+		case "stack":
+			g.Stacks = nums
+		case "blind":
+			g.Blinds = nums
+		case "raisesize":
+			g.RaiseSizes = nums
+		case "maxraises":
+			g.MaxRaises = nums
+		case "numboardcards":
+			g.BoardCards = nums
+		case "firstplayer":
+			g.FirstPlayer = make([]int, len(nums))
+			for i, v := range nums {
+				// positions are numbered from 1 in game definitions
+				g.FirstPlayer[i] = v - 1
+			}
+		default:
+			return Game{}, fmt.Errorf("%w: unknown key %q", ErrInvalidGame, key)
+		}
+		if err != nil {
+			return Game{}, fmt.Errorf("%w: %s: %v", ErrInvalidGame, key, err)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return Game{}, err
+	}
+	return Game{}, fmt.Errorf("%w: expected END GAMEDEF", ErrInvalidGame)
+}
+
+// defaults fills in the values left out of a definition and checks the lists have a value for
+// each player or round.
+func (g *Game) defaults() error {
+	if g.Players < 2 {
+		return fmt.Errorf("%w: %d players", ErrInvalidGame, g.Players)
+	}
+	if g.Rounds < 1 {
+		return fmt.Errorf("%w: %d rounds", ErrInvalidGame, g.Rounds)
+	}
+	if len(g.Stacks) == 0 {
+		g.Stacks = repeat(math.MaxInt32, g.Players)
+	}
+	if len(g.MaxRaises) == 0 {
+		g.MaxRaises = repeat(math.MaxUint8, g.Rounds)
+	}
+	for name, v := range map[string][]int{"stack": g.Stacks, "blind": g.Blinds} {
+		if len(v) != g.Players {
+			return fmt.Errorf("%w: %s needs a value for each of %d players", ErrInvalidGame, name, g.Players)
+		}
+	}
+	rounds := map[string][]int{"firstPlayer": g.FirstPlayer, "maxRaises": g.MaxRaises, "numBoardCards": g.BoardCards}
+	if g.Limit == hand.FixedLimit {
+		rounds["raiseSize"] = g.RaiseSizes
+	}
+	for name, v := range rounds {
+		if len(v) != g.Rounds {
+			return fmt.Errorf("%w: %s needs a value for each of %d rounds", ErrInvalidGame, name, g.Rounds)
+		}
+	}
+	return nil
+}
+
+// Validate returns an error wrapping ErrUnsupportedGame describing the first difference between
+// the game and the Texas Hold'em dealt by package hand.
+func (g Game) Validate() error {
+	switch {
+	case g.Rounds != 4 || !equal(g.BoardCards, []int{0, 3, 1, 1}):
+		return fmt.Errorf("%w: rounds must deal 0, 3, 1 and 1 board cards", ErrUnsupportedGame)
+	case g.Suits != 4 || g.Ranks != 13 || g.HoleCards != 2:
+		return fmt.Errorf("%w: must deal 2 hole cards from a standard deck", ErrUnsupportedGame)
+	case 2*g.Players+5 > 52:
+		return fmt.Errorf("%w: too many players to deal to", ErrUnsupportedGame)
+	}
+	for i, v := range g.Stacks {
+		if v <= 0 {
+			return fmt.Errorf("%w: stack of position %d must be positive", ErrUnsupportedGame, i)
+		}
+	}
+	if g.Limit == hand.FixedLimit {
+		bb := g.bigBlind()
+		if !equal(g.RaiseSizes, []int{bb, bb, 2 * bb, 2 * bb}) {
+			return fmt.Errorf("%w: raises must be the big blind before the turn and twice it after", ErrUnsupportedGame)
+		}
+	}
+	if _, _, ok := g.order(); !ok {
+		return fmt.Errorf("%w: blinds and first players are not those of a standard game", ErrUnsupportedGame)
+	}
+	return nil
+}
+
+// rules returns the rules of each hand of the game.
+func (g Game) rules() hand.Rules {
+	_, blinds, _ := g.order()
+	return hand.Rules{Limit: g.Limit, Blinds: blinds, PreflopBetting: true}
+}
+
+// order returns the positions in the order a hand is dealt to them, from the dealer, and the
+// blinds they post in that order. It reports whether the blinds and first players of the game
+// match those of the hand.
+func (g Game) order() ([]int, []int, bool) {
+	candidates := [][]int{make([]int, g.Players)}
+	for i := range candidates[0] {
+		candidates[0][i] = i
+	}
+	if g.Players == 2 {
+		// heads up games usually list the big blind first
+		candidates = append(candidates, []int{1, 0})
+	}
+	for _, order := range candidates {
+		var blinds []int
+		for _, pos := range order {
+			blinds = append(blinds, g.Blinds[pos])
+		}
+		for len(blinds) > 0 && blinds[len(blinds)-1] == 0 {
+			blinds = blinds[:len(blinds)-1]
+		}
+		if g.firstPlayers(order, blinds) {
+			return order, blinds, true
+		}
+	}
+	return nil, nil, false
+}
+
+// firstPlayers reports whether a hand dealt to the positions in order, with the blinds, is first
+// played by the positions of the game on each round.
+func (g Game) firstPlayers(order []int, blinds []int) bool {
+	for _, v := range blinds {
+		if v <= 0 {
+			return false
+		}
+	}
+	// the player after the last blind acts first before the flop and the dealer, or heads up the
+	// big blind, afterwards
+	first := []int{order[len(blinds)%len(order)], order[0], order[0], order[0]}
+	if len(order) == 2 && len(blinds) == 2 {
+		first[1], first[2], first[3] = order[1], order[1], order[1]
+	}
+	return equal(first, g.FirstPlayer)
+}
+
+func (g Game) bigBlind() int {
+	var bb int
+	for _, v := range g.Blinds {
+		if v > bb {
+			bb = v
+		}
+	}
+	return bb
+}
+
+func parseInts(s string) ([]int, error) {
+	var nums []int
+	for _, f := range strings.Fields(s) {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, err
+		}
+		nums = append(nums, n)
+	}
+	return nums, nil
+}
+
+func single(nums []int, v *int) error {
+	if len(nums) != 1 {
+		return errors.New("expected a single value")
+	}
+	*v = nums[0]
+	return nil
+}
+
+func repeat(v, n int) []int {
+	out := make([]int, n)
+	for i := range out {
+		out[i] = v
+	}
+	return out
+}
+
+func equal(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+package acpc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/timothysugar/hand/pkg/hand"
+)
+
+// matchState is a hand as described by the protocol.
+type matchState struct {
+	hand int
+	// betting holds the actions of each round reached.
+	betting []string
+	hole    [][]hand.Card
+	// shown reports whether the hole cards of each position are revealed to all.
+	shown []bool
+	board []hand.Card
+}
+
+// String returns the state as seen by the given position.
+func (s *matchState) String(position int) string {
+	return fmt.Sprintf("MATCHSTATE:%d:%d:%s:%s", position, s.hand, s.bettingString(), s.cards(position))
+}
+
+// send sends each agent the state as seen by their position.
+func (s *matchState) send(positions []*agent) error {
+	for i, a := range positions {
+		if err := a.send(s.String(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// play records an action on the current round and moves to the rounds dealt on the board.
+func (s *matchState) play(action string, board []hand.Card) {
+	s.betting[len(s.betting)-1] += action
+	s.board = board
+	for len(s.betting) <= round(len(board)) {
+		s.betting = append(s.betting, "")
+	}
+}
+
+// raises returns the number of raises on the current round.
+func (s *matchState) raises() int {
+	return strings.Count(s.betting[len(s.betting)-1], "r")
+}
+
+func (s *matchState) bettingString() string {
+	return strings.Join(s.betting, "/")
+}
+
+// cards returns the hole cards visible to the position, or every position's if it is negative,
+// followed by the board of each round.
+func (s *matchState) cards(position int) string {
+	hole := make([]string, len(s.hole))
+	for i, cs := range s.hole {
+		if position < 0 || i == position || (s.shown != nil && s.shown[i]) {
+			hole[i] = cardString(cs)
+		}
+	}
+	var sb strings.Builder
+	sb.WriteString(strings.Join(hole, "|"))
+	dealt := 0
+	for r := 1; r < len(s.betting); r++ {
+		n := boardCards[r]
+		sb.WriteString("/")
+		sb.WriteString(cardString(s.board[dealt : dealt+n]))
+		dealt += n
+	}
+	return sb.String()
+}
+
+// boardCards are the cards dealt to the board on each round.
+var boardCards = []int{0, 3, 1, 1}
+
+// round returns the round on which the board has the given number of cards.
+func round(board int) int {
+	for r, dealt := 0, 0; r < len(boardCards); r++ {
+		if dealt += boardCards[r]; dealt == board {
+			return r
+		}
+	}
+	return len(boardCards) - 1
+}
+
+// cardString returns the cards in the protocol's notation, such as "AsTd".
+func cardString(cs []hand.Card) string {
+	var sb strings.Builder
+	for _, c := range cs {
+		sb.WriteString(c.String())
+	}
+	return sb.String()
+}
+
+func join(values []int) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = strconv.Itoa(v)
+	}
+	return strings.Join(s, "|")
+}
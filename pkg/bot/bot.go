@@ -0,0 +1,272 @@
+// Package bot plays hands with bots which run as external programs, speaking a line based text
+// protocol over their standard input and output so that they may be written in any language.
+//
+// When started the harness sends the protocol version and the bot replies that it is ready:
+//
+//	protocol 1
+//	ready <name>
+//
+// Each time the bot must act the harness sends the state of the hand as the bot sees it, its
+// valid moves and the time it has to reply in milliseconds, ending with a go line:
+//
+//	hand <hand id>
+//	street <street>
+//	board <cards>
+//	pot <chips>
+//	self <player id> <chips> <committed> <cards>
+//	opponent <player id> <chips> <committed> <folded|active> <cards>
+//	moves <action>:<min>-<max> ...
+//	go <seq> <milliseconds>
+//
+// There is an opponent line for each opponent. Cards are written as rank then suit, such as "As"
+// or "Td", separated by spaces, with "??" for a card which is face down and "-" for no cards. The
+// bot replies with the sequence number of the go line, the action and, for actions with chips, the
+// chips played:
+//
+//	action <seq> <action> [<chips>]
+//
+// Actions are named as by hand.Action, in any case. A reply which is not a valid move or which
+// arrives after the deadline folds the bot, and is logged. Replies to earlier go lines are ignored.
+package bot
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/timothysugar/hand/pkg/hand"
+)
+
+// ProtocolVersion is the version of the protocol spoken by the harness.
+const ProtocolVersion = 1
+
+// DefaultDeadline is the time a bot has to reply if no deadline is given.
+const DefaultDeadline = 5 * time.Second
+
+// ErrNotReady is returned when a bot does not reply that it is ready.
+var ErrNotReady = errors.New("bot not ready")
+
+// External is a strategy played by a bot speaking the protocol. It is safe for concurrent use,
+// though the bot is asked for one move at a time.
+type External struct {
+	// Name is the name the bot gave when it was ready.
+	Name string
+	// Deadline is the time the bot has to reply to each request for a move.
+	Deadline time.Duration
+	// Log records illegal and late replies. The standard logger is used if it is nil.
+	Log *log.Logger
+
+	m      sync.Mutex
+	w      io.Writer
+	lines  chan string
+	seq    int
+	closer func() error
+}
+
+// New creates a strategy for the bot which is sent requests on w and replies on r, and waits for
+// it to be ready for at most the deadline.
+func New(r io.Reader, w io.Writer, deadline time.Duration) (*External, error) {
+	if deadline <= 0 {
+		deadline = DefaultDeadline
+	}
+	b := &External{Deadline: deadline, w: w, lines: make(chan string, 16)}
+	go b.read(r)
+	if _, err := fmt.Fprintf(w, "protocol %d\n", ProtocolVersion); err != nil {
+		return nil, err
+	}
+	select {
+	case l, ok := <-b.lines:
+		fields := strings.Fields(l)
+		if !ok || len(fields) == 0 || fields[0] != "ready" {
+			return nil, fmt.Errorf("%w: replied %q", ErrNotReady, l)
+		}
+		b.Name = strings.Join(fields[1:], " ")
+	case <-time.After(deadline):
+		return nil, fmt.Errorf("%w: no reply within %v", ErrNotReady, deadline)
+	}
+	return b, nil
+}
+
+// Start runs the program as a bot until the context is done or the bot is closed.
+func Start(ctx context.Context, deadline time.Duration, name string, args ...string) (*External, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stderr = os.Stderr
+	w, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	r, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	b, err := New(r, w, deadline)
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return nil, err
+	}
+	b.closer = func() error {
+		w.Close()
+		return cmd.Wait()
+	}
+	return b, nil
+}
+
+// Close closes the bot's input and waits for it to exit if it was started by the harness.
+func (b *External) Close() error {
+	if b.closer == nil {
+		return nil
+	}
+	return b.closer()
+}
+
+// Decide asks the bot for its move. The bot folds if it does not reply with a valid move in time,
+// or posts its blind if one is required.
+func (b *External) Decide(v hand.View, moves []hand.Move) hand.Input {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	b.seq++
+	if _, err := io.WriteString(b.w, request(v, moves, b.seq, b.Deadline)); err != nil {
+		return b.forfeit(moves, "could not be sent the request: %v", err)
+	}
+	timeout := time.After(b.Deadline)
+	for {
+		select {
+		case l, ok := <-b.lines:
+			if !ok {
+				return b.forfeit(moves, "exited")
+			}
+			seq, inp, err := parseReply(l)
+			if err != nil {
+				return b.forfeit(moves, "replied %q: %v", l, err)
+			}
+			if seq != b.seq {
+				continue
+			}
+			inp, ok = legal(inp, moves)
+			if !ok {
+				return b.forfeit(moves, "replied with %v which is not a valid move", inp)
+			}
+			return inp
+		case <-timeout:
+			return b.forfeit(moves, "did not reply within %v", b.Deadline)
+		}
+	}
+}
+
+// forfeit logs why the bot is folded and returns the input to fold, or to post a required blind.
+func (b *External) forfeit(moves []hand.Move, format string, a ...any) hand.Input {
+	logger := b.Log
+	if logger == nil {
+		logger = log.Default()
+	}
+	logger.Printf("bot %s %s so folding", b.Name, fmt.Sprintf(format, a...))
+	for _, m := range moves {
+		if m.Action == hand.Blind {
+			return hand.Input{Action: hand.Blind, Chips: m.Bet.Minimum}
+		}
+	}
+	return hand.Input{Action: hand.Fold}
+}
+
+func (b *External) read(r io.Reader) {
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		b.lines <- s.Text()
+	}
+	close(b.lines)
+}
+
+// request returns the lines sent to the bot when it must act.
+func request(v hand.View, moves []hand.Move, seq int, deadline time.Duration) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "hand %s\n", v.HandId)
+	fmt.Fprintf(&sb, "street %s\n", v.Street)
+	fmt.Fprintf(&sb, "board %s\n", cards(v.Board, 0))
+	fmt.Fprintf(&sb, "pot %d\n", v.Pot)
+	fmt.Fprintf(&sb, "self %s %d %d %s\n", v.Self.Id, v.Self.Chips, v.Self.Committed, cards(v.Self.Cards, 0))
+	for _, o := range v.Opponents {
+		status := "active"
+		if o.Folded {
+			status = "folded"
+		}
+		fmt.Fprintf(&sb, "opponent %s %d %d %s %s\n", o.Id, o.Chips, o.Committed, status, cards(o.Cards, o.FaceDown))
+	}
+	sb.WriteString("moves")
+	for _, m := range moves {
+		fmt.Fprintf(&sb, " %s:%d-%d", strings.ToLower(m.Action.String()), m.Bet.Minimum, m.Bet.Maximum)
+	}
+	fmt.Fprintf(&sb, "\ngo %d %d\n", seq, deadline.Milliseconds())
+	return sb.String()
+}
+
+// parseReply returns the sequence number and input of a reply from the bot.
+func parseReply(l string) (int, hand.Input, error) {
+	fields := strings.Fields(l)
+	if len(fields) < 3 || len(fields) > 4 || fields[0] != "action" {
+		return 0, hand.Input{}, errors.New("expected action <seq> <action> [<chips>]")
+	}
+	seq, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, hand.Input{}, fmt.Errorf("sequence number: %w", err)
+	}
+	inp := hand.Input{Action: hand.Undefined}
+	for a := hand.Blind; a <= hand.AllIn; a++ {
+		if strings.EqualFold(a.String(), fields[2]) {
+			inp.Action = a
+		}
+	}
+	if inp.Action == hand.Undefined {
+		return 0, hand.Input{}, fmt.Errorf("unsupported action %q", fields[2])
+	}
+	if len(fields) == 4 {
+		if inp.Chips, err = strconv.Atoi(fields[3]); err != nil {
+			return 0, hand.Input{}, fmt.Errorf("chips: %w", err)
+		}
+	}
+	return seq, inp, nil
+}
+
+// legal returns the input with the chips of the move if it is one of the moves with chips in its
+// range. Chips may be left out of moves which only allow one amount, and are ignored by moves
+// which do not take a bet.
+func legal(inp hand.Input, moves []hand.Move) (hand.Input, bool) {
+	for _, m := range moves {
+		if m.Action != inp.Action {
+			continue
+		}
+		if inp.Chips == 0 && m.Bet.Minimum == m.Bet.Maximum {
+			inp.Chips = m.Bet.Minimum
+		}
+		return inp, inp.Chips >= m.Bet.Minimum && inp.Chips <= m.Bet.Maximum
+	}
+	return inp, false
+}
+
+// cards returns the cards in the protocol's notation followed by the given number face down.
+func cards(cs []hand.Card, faceDown int) string {
+	var out []string
+	for _, c := range cs {
+		out = append(out, c.String())
+	}
+	for i := 0; i < faceDown; i++ {
+		out = append(out, "??")
+	}
+	if len(out) == 0 {
+		return "-"
+	}
+	return strings.Join(out, " ")
+}
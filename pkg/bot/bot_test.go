@@ -0,0 +1,162 @@
+package bot
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timothysugar/hand/pkg/hand"
+)
+
+// fakeBot connects a bot to the harness which replies to each go line using reply, and returns
+// the requests it was sent on the channel.
+func fakeBot(t *testing.T, ready string, reply func(seq int) string) (*External, chan []string) {
+	t.Helper()
+	toBot, botIn := io.Pipe()
+	botOut, fromBot := io.Pipe()
+	t.Cleanup(func() {
+		botIn.Close()
+		fromBot.Close()
+	})
+	requests := make(chan []string, 8)
+	go func() {
+		s := bufio.NewScanner(toBot)
+		s.Scan()
+		fmt.Fprintln(fromBot, ready)
+		var lines []string
+		for s.Scan() {
+			lines = append(lines, s.Text())
+			var seq int
+			if _, err := fmt.Sscanf(s.Text(), "go %d", &seq); err != nil {
+				continue
+			}
+			requests <- lines
+			lines = nil
+			if r := reply(seq); r != "" {
+				fmt.Fprintln(fromBot, r)
+			}
+		}
+	}()
+	b, err := New(botOut, botIn, 100*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Log = log.New(io.Discard, "", 0)
+	return b, requests
+}
+
+var moves = []hand.Move{
+	hand.NewMove(hand.Fold, hand.NewExactBet(0)),
+	hand.NewMove(hand.Call, hand.NewExactBet(2)),
+	hand.NewMove(hand.Raise, hand.NewBetRange(4, 100)),
+	hand.NewMove(hand.AllIn, hand.NewExactBet(100)),
+}
+
+func TestNewReadsNameOfReadyBot(t *testing.T) {
+	b, _ := fakeBot(t, "ready Deep Stack", func(int) string { return "" })
+
+	if b.Name != "Deep Stack" {
+		t.Errorf("expected name Deep Stack but got %q", b.Name)
+	}
+}
+
+func TestNewFailsWhenBotIsNotReady(t *testing.T) {
+	toBot, botIn := io.Pipe()
+	botOut, fromBot := io.Pipe()
+	defer botIn.Close()
+	go func() {
+		bufio.NewScanner(toBot).Scan()
+		fmt.Fprintln(fromBot, "hello")
+	}()
+
+	if _, err := New(botOut, botIn, time.Second); !errors.Is(err, ErrNotReady) {
+		t.Errorf("expected %v but got %v", ErrNotReady, err)
+	}
+}
+
+func TestDecidePlaysReply(t *testing.T) {
+	b, requests := fakeBot(t, "ready raiser", func(seq int) string {
+		return fmt.Sprintf("action %d RAISE 10", seq)
+	})
+	v := hand.View{
+		HandId: "h1",
+		Street: hand.Flop,
+		Board:  []hand.Card{{Suit: hand.Spades, Rank: hand.Ace}, {Suit: hand.Hearts, Rank: hand.Ten}, {}},
+		Pot:    3,
+		Self:   hand.SeatView{Id: "p1", Chips: 100, Cards: []hand.Card{{Suit: hand.Clubs, Rank: hand.Two}, {Suit: hand.Diamonds, Rank: hand.King}}},
+		Opponents: []hand.SeatView{
+			{Id: "p2", Chips: 98, Committed: 2, FaceDown: 2},
+		},
+	}
+
+	got := b.Decide(v, moves)
+
+	if want := (hand.Input{Action: hand.Raise, Chips: 10}); got != want {
+		t.Errorf("expected %v but got %v", want, got)
+	}
+	req := strings.Join(<-requests, "\n")
+	for _, want := range []string{"board As Th ??", "self p1 100 0 2c Kd", "opponent p2 98 2 active ?? ??", "moves fold:0-0 call:2-2 raise:4-100 allin:100-100", "go 1 100"} {
+		if !strings.Contains(req, want) {
+			t.Errorf("expected request to contain %q but got\n%s", want, req)
+		}
+	}
+}
+
+func TestDecideFillsChipsOfExactMove(t *testing.T) {
+	b, _ := fakeBot(t, "ready caller", func(seq int) string {
+		return fmt.Sprintf("action %d call", seq)
+	})
+
+	if got, want := b.Decide(hand.View{}, moves), (hand.Input{Action: hand.Call, Chips: 2}); got != want {
+		t.Errorf("expected %v but got %v", want, got)
+	}
+}
+
+func TestDecideFoldsOnIllegalReply(t *testing.T) {
+	tests := []string{"action %d raise 1000", "action %d check", "action %d shove", "pass"}
+	for _, reply := range tests {
+		b, _ := fakeBot(t, "ready cheat", func(seq int) string {
+			if strings.Contains(reply, "%d") {
+				return fmt.Sprintf(reply, seq)
+			}
+			return reply
+		})
+
+		if got := b.Decide(hand.View{}, moves); got.Action != hand.Fold {
+			t.Errorf("expected reply %q to fold but got %v", reply, got)
+		}
+	}
+}
+
+func TestDecideFoldsWhenLate(t *testing.T) {
+	b, _ := fakeBot(t, "ready sleeper", func(seq int) string {
+		if seq == 1 {
+			time.Sleep(150 * time.Millisecond)
+			return fmt.Sprintf("action %d raise 50", seq)
+		}
+		return fmt.Sprintf("action %d call", seq)
+	})
+
+	if got := b.Decide(hand.View{}, moves); got.Action != hand.Fold {
+		t.Errorf("expected late bot to fold but got %v", got)
+	}
+	// the late reply to the first request is ignored
+	if got, want := b.Decide(hand.View{}, moves), (hand.Input{Action: hand.Call, Chips: 2}); got != want {
+		t.Errorf("expected %v but got %v", want, got)
+	}
+}
+
+func TestDecidePostsRequiredBlindWhenForfeiting(t *testing.T) {
+	b, _ := fakeBot(t, "ready quiet", func(seq int) string { return fmt.Sprintf("action %d fold", seq) })
+
+	got := b.Decide(hand.View{}, []hand.Move{hand.NewMove(hand.Blind, hand.NewExactBet(1))})
+
+	if want := (hand.Input{Action: hand.Blind, Chips: 1}); got != want {
+		t.Errorf("expected %v but got %v", want, got)
+	}
+}
@@ -0,0 +1,238 @@
+// Package equity calculates the share of the pot each of two or more hands can expect to win when
+// the rest of the board is dealt.
+package equity
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/timothysugar/hand/pkg/hand"
+)
+
+var (
+	// ErrNotEnoughHands is returned when calculating the equity of fewer than two hands.
+	ErrNotEnoughHands = errors.New("equity requires at least 2 hands")
+	// ErrInvalidHand is returned when a hand has the wrong number of hole cards for the variant.
+	ErrInvalidHand = errors.New("invalid hand")
+	// ErrInvalidBoard is returned when the board has more than five cards.
+	ErrInvalidBoard = errors.New("invalid board")
+	// ErrDuplicateCard is returned when a card appears more than once among the hands, board and
+	// dead cards.
+	ErrDuplicateCard = errors.New("duplicate card")
+	// ErrNotEnoughCards is returned when too few cards remain in the deck to complete the board.
+	ErrNotEnoughCards = errors.New("not enough cards")
+)
+
+// DefaultSamples is the number of boards sampled when enumerating every board is not feasible and
+// no number of samples is given.
+const DefaultSamples = 20000
+
+// Options configure an equity calculation.
+type Options struct {
+	Variant hand.Variant
+	// Board is the part of the board already dealt, of up to five cards.
+	Board []hand.Card
+	// Dead cards are known to be out of the deck, such as those folded or exposed.
+	Dead []hand.Card
+	// Samples is the most boards to enumerate. If there are more possible boards then this many
+	// are sampled at random instead. DefaultSamples is used if it is 0.
+	Samples int
+	// Seed seeds the sampling of boards so that results can be reproduced.
+	Seed int64
+}
+
+// Result is the outcome for a hand over every board dealt.
+type Result struct {
+	// Win, Tie and Lose are the fractions of boards on which the hand wins outright, ties for the
+	// best hand or loses.
+	Win, Tie, Lose float64
+	// Equity is the expected share of the pot, counting a tie between n hands as 1/n of a win.
+	Equity float64
+}
+
+// Calculation is the result of an equity calculation for each hand in the order given.
+type Calculation struct {
+	Results []Result
+	// Boards is the number of boards dealt.
+	Boards int
+	// Exhaustive reports whether every possible board was dealt rather than a sample.
+	Exhaustive bool
+}
+
+// Calculate returns the equity of each hand with the rest of the board dealt from the cards which
+// are not known. Every possible board is enumerated if there are at most as many as the number of
+// samples, otherwise boards are sampled at random.
+func Calculate(hands [][]hand.Card, opts Options) (Calculation, error) {
+	if len(hands) < 2 {
+		return Calculation{}, ErrNotEnoughHands
+	}
+	holeCards := 2
+	if opts.Variant == hand.Omaha {
+		holeCards = 4
+	}
+	for i, v := range hands {
+		if len(v) != holeCards {
+			return Calculation{}, fmt.Errorf("%w: hand %d has %d cards but %d are required", ErrInvalidHand, i, len(v), holeCards)
+		}
+	}
+	if len(opts.Board) > 5 {
+		return Calculation{}, fmt.Errorf("%w: %d cards", ErrInvalidBoard, len(opts.Board))
+	}
+	known := append(append([]hand.Card{}, opts.Board...), opts.Dead...)
+	for _, v := range hands {
+		known = append(known, v...)
+	}
+	deck, err := remaining(known)
+	if err != nil {
+		return Calculation{}, err
+	}
+
+	missing := 5 - len(opts.Board)
+	if len(deck) < missing {
+		return Calculation{}, fmt.Errorf("%w: %d cards remain to deal %d to the board", ErrNotEnoughCards, len(deck), missing)
+	}
+
+	samples := opts.Samples
+	if samples <= 0 {
+		samples = DefaultSamples
+	}
+	e := newEvaluation(len(hands), opts)
+	if boards := choose(len(deck), missing); boards <= samples {
+		e.enumerate(deck, func() { e.evaluate(hands, 1) })
+		return e.calculation(true), nil
+	}
+	r := rand.New(rand.NewSource(opts.Seed))
+	for i := 0; i < samples; i++ {
+		e.sample(r, deck)
+		e.evaluate(hands, 1)
+	}
+	return e.calculation(false), nil
+}
+
+// remaining returns the cards of the deck which are not known, or an error if a known card is
+// repeated or not in the deck.
+func remaining(known []hand.Card) ([]hand.Card, error) {
+	seen := make(map[hand.Card]bool)
+	for _, c := range known {
+		if seen[c] {
+			return nil, fmt.Errorf("%w: %v", ErrDuplicateCard, c)
+		}
+		seen[c] = true
+	}
+	var deck []hand.Card
+	for _, c := range hand.FullDeck() {
+		if !seen[c] {
+			deck = append(deck, c)
+		}
+	}
+	if len(deck)+len(known) != 52 {
+		return nil, fmt.Errorf("%w: cards must be from a standard deck", ErrInvalidHand)
+	}
+	return deck, nil
+}
+
+// evaluation accumulates the outcomes of the hands over the boards dealt, weighted by the
+// likelihood of the hands.
+type evaluation struct {
+	variant hand.Variant
+	known   int
+	board   []hand.Card
+	ranks   []hand.HandRank
+	wins    []float64
+	ties    []float64
+	equity  []float64
+	weight  float64
+	boards  int
+}
+
+func newEvaluation(hands int, opts Options) *evaluation {
+	board := make([]hand.Card, 5)
+	copy(board, opts.Board)
+	return &evaluation{
+		variant: opts.Variant,
+		known:   len(opts.Board),
+		board:   board,
+		ranks:   make([]hand.HandRank, hands),
+		wins:    make([]float64, hands),
+		ties:    make([]float64, hands),
+		equity:  make([]float64, hands),
+	}
+}
+
+// enumerate calls fn with each board that can be completed from the deck.
+func (e *evaluation) enumerate(deck []hand.Card, fn func()) {
+	hand.Combinations(len(deck), len(e.board)-e.known, func(idx []int) {
+		for i, v := range idx {
+			e.board[e.known+i] = deck[v]
+		}
+		fn()
+	})
+}
+
+// sample completes the board with cards drawn at random from the deck, which is reordered.
+func (e *evaluation) sample(r *rand.Rand, deck []hand.Card) {
+	// a partial shuffle draws the missing cards without replacement
+	for j := 0; j < len(e.board)-e.known; j++ {
+		k := j + r.Intn(len(deck)-j)
+		deck[j], deck[k] = deck[k], deck[j]
+		e.board[e.known+j] = deck[j]
+	}
+}
+
+// evaluate records the outcome of the hands on the current board with the given weight.
+func (e *evaluation) evaluate(hands [][]hand.Card, weight float64) {
+	var best hand.HandRank
+	for i, v := range hands {
+		if e.variant == hand.Omaha {
+			e.ranks[i] = hand.EvaluateOmaha(v, e.board)
+		} else {
+			e.ranks[i] = hand.Evaluate(append(append(make([]hand.Card, 0, 7), v...), e.board...))
+		}
+		if e.ranks[i] > best {
+			best = e.ranks[i]
+		}
+	}
+	winners := 0
+	for _, v := range e.ranks {
+		if v == best {
+			winners++
+		}
+	}
+	for i, v := range e.ranks {
+		if v != best {
+			continue
+		}
+		if winners == 1 {
+			e.wins[i] += weight
+		} else {
+			e.ties[i] += weight
+		}
+		e.equity[i] += weight / float64(winners)
+	}
+	e.weight += weight
+	e.boards++
+}
+
+func (e *evaluation) calculation(exhaustive bool) Calculation {
+	c := Calculation{Boards: e.boards, Exhaustive: exhaustive}
+	n := e.weight
+	for i := range e.ranks {
+		c.Results = append(c.Results, Result{
+			Win:    e.wins[i] / n,
+			Tie:    e.ties[i] / n,
+			Lose:   (n - e.wins[i] - e.ties[i]) / n,
+			Equity: e.equity[i] / n,
+		})
+	}
+	return c
+}
+
+// choose returns the number of combinations of k of n items.
+func choose(n, k int) int {
+	c := 1
+	for i := 0; i < k; i++ {
+		c = c * (n - i) / (i + 1)
+	}
+	return c
+}
@@ -0,0 +1,227 @@
+package equity
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/timothysugar/hand/pkg/hand"
+)
+
+func cards(cs ...string) []hand.Card {
+	var out []hand.Card
+	for _, c := range cs {
+		card, err := hand.ParseCard(c)
+		if err != nil {
+			panic(err)
+		}
+		out = append(out, card)
+	}
+	return out
+}
+
+func TestCalculateOnCompleteBoard(t *testing.T) {
+	hands := [][]hand.Card{cards("As", "Ad"), cards("Ks", "Kd")}
+
+	got, err := Calculate(hands, Options{Board: cards("2c", "7h", "9d", "Jc", "Kh")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Boards != 1 || !got.Exhaustive {
+		t.Errorf("expected the single board to be evaluated but got %+v", got)
+	}
+	if got.Results[0].Lose != 1 || got.Results[1].Win != 1 {
+		t.Errorf("expected kings to make a set and win but got %+v", got.Results)
+	}
+}
+
+func TestCalculateEnumeratesRiversExhaustively(t *testing.T) {
+	hands := [][]hand.Card{cards("As", "Ad"), cards("Ks", "Kd")}
+	board := cards("2c", "7h", "9d", "Jc")
+
+	got, err := Calculate(hands, Options{Board: board})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the kings win only with one of the two remaining kings from 44 unseen cards
+	if !got.Exhaustive || got.Boards != 44 {
+		t.Fatalf("expected 44 rivers to be enumerated but got %+v", got)
+	}
+	if want := 2.0 / 44; math.Abs(got.Results[1].Equity-want) > 1e-9 {
+		t.Errorf("expected equity of %f but got %f", want, got.Results[1].Equity)
+	}
+}
+
+func TestCalculateExcludesDeadCards(t *testing.T) {
+	hands := [][]hand.Card{cards("As", "Ad"), cards("Ks", "Kd")}
+	board := cards("2c", "7h", "9d", "Jc")
+
+	got, err := Calculate(hands, Options{Board: board, Dead: cards("Kc", "Kh")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Boards != 42 || got.Results[0].Equity != 1 {
+		t.Errorf("expected aces to win on every river once the kings are dead but got %+v", got)
+	}
+}
+
+func TestCalculateSamplesReproduciblyWithSeed(t *testing.T) {
+	hands := [][]hand.Card{cards("As", "Ad"), cards("Ks", "Kd")}
+	opts := Options{Samples: 5000, Seed: 7}
+
+	first, err := Calculate(hands, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := Calculate(hands, opts)
+
+	if first.Exhaustive || first.Boards != opts.Samples {
+		t.Errorf("expected %d sampled boards but got %+v", opts.Samples, first)
+	}
+	if first.Results[0] != second.Results[0] {
+		t.Errorf("expected the same seed to give the same result but got %v and %v", first.Results[0], second.Results[0])
+	}
+	if eq := first.Results[0].Equity; eq < 0.78 || eq > 0.85 {
+		t.Errorf("expected aces to have about 82%% equity against kings but got %f", eq)
+	}
+}
+
+func TestCalculateOmahaHands(t *testing.T) {
+	hands := [][]hand.Card{cards("Ah", "Kh", "2c", "3d"), cards("Qs", "Qd", "8c", "8d")}
+	board := cards("Th", "9h", "4s", "5c", "Qc")
+
+	got, err := Calculate(hands, Options{Variant: hand.Omaha, Board: board})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// two hearts in hand need three on the board to make a flush
+	if got.Results[1].Win != 1 {
+		t.Errorf("expected set of queens to win but got %+v", got.Results)
+	}
+}
+
+func TestCalculateRejectsDuplicateCards(t *testing.T) {
+	hands := [][]hand.Card{cards("As", "Ad"), cards("As", "Kd")}
+
+	if _, err := Calculate(hands, Options{}); !errors.Is(err, ErrDuplicateCard) {
+		t.Errorf("expected %v but got %v", ErrDuplicateCard, err)
+	}
+}
+
+func TestCalculateRejectsTooManyHandsForBoard(t *testing.T) {
+	deck := hand.FullDeck()
+	hands := make([][]hand.Card, 12)
+	for i := range hands {
+		hands[i] = deck[4*i : 4*i+4]
+	}
+
+	if _, err := Calculate(hands, Options{Variant: hand.Omaha}); !errors.Is(err, ErrNotEnoughCards) {
+		t.Errorf("expected %v but got %v", ErrNotEnoughCards, err)
+	}
+}
+
+func TestParseRangeExpandsNotation(t *testing.T) {
+	tests := []struct {
+		notation string
+		combos   int
+	}{
+		{"TT+", 30},
+		{"AKs", 4},
+		{"KQo", 12},
+		{"AK", 16},
+		{"A5s-A2s", 16},
+		{"55-22", 24},
+		{"A9s+", 20},
+		{"AsKs", 1},
+		{"KsAs, AsKs", 1},
+		{"AKs, KsAs", 4},
+		{"AA, AdAc", 6},
+		{"TT+, AKs, KQo, A5s-A2s", 62},
+	}
+	for _, tt := range tests {
+		r, err := ParseRange(tt.notation)
+		if err != nil {
+			t.Errorf("expected %q to parse but got %v", tt.notation, err)
+			continue
+		}
+		if len(r) != tt.combos {
+			t.Errorf("expected %q to have %d combos but got %d", tt.notation, tt.combos, len(r))
+		}
+	}
+
+	for _, notation := range []string{"AX", "AAs", "A5s-K2s", "AKs:2", "AsAs"} {
+		if _, err := ParseRange(notation); !errors.Is(err, ErrInvalidRange) {
+			t.Errorf("expected %q to be %v but got %v", notation, ErrInvalidRange, err)
+		}
+	}
+}
+
+func TestParseRangeGivesSpecificCardsTheirLastWeight(t *testing.T) {
+	r, err := ParseRange("AKs, KsAs:0.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range r {
+		want := 1.0
+		if c.Cards == [2]hand.Card{cards("As")[0], cards("Ks")[0]} {
+			want = 0.5
+		}
+		if c.Weight != want {
+			t.Errorf("expected %v to have weight %f but got %f", c.Cards, want, c.Weight)
+		}
+	}
+}
+
+func TestRangeWithoutRemovesCombosUsingKnownCards(t *testing.T) {
+	r, err := ParseRange("AA, AKo:0.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := r.Without(cards("As"))
+
+	if len(got) != 3+9 {
+		t.Errorf("expected 3 pairs and 9 offsuit hands without the ace of spades but got %d", len(got))
+	}
+	if got[len(got)-1].Weight != 0.5 {
+		t.Errorf("expected weight of 0.5 but got %f", got[len(got)-1].Weight)
+	}
+}
+
+func TestCalculateHandAgainstRange(t *testing.T) {
+	aces := cards("As", "Ad")
+	kings, _ := ParseRange("KK")
+	board := cards("2c", "7h", "9d", "Jc")
+
+	got, err := CalculateRanges([]Range{Exact(aces[0], aces[1]), kings}, Options{Board: board})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// each combination of kings leaves two kings among 44 unseen rivers
+	if !got.Exhaustive || got.Boards != 6*44 {
+		t.Fatalf("expected every combo and river to be enumerated but got %+v", got)
+	}
+	if want := 2.0 / 44; math.Abs(got.Results[1].Equity-want) > 1e-9 {
+		t.Errorf("expected equity of %f but got %f", want, got.Results[1].Equity)
+	}
+}
+
+func TestCalculateRangeAgainstRange(t *testing.T) {
+	aces, _ := ParseRange("AA")
+	kings, _ := ParseRange("KK")
+
+	got, err := CalculateRanges([]Range{aces, kings}, Options{Samples: 5000, Seed: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if eq := got.Results[0].Equity; got.Exhaustive || eq < 0.78 || eq > 0.85 {
+		t.Errorf("expected sampled equity of about 82%% for aces but got %+v", got)
+	}
+}
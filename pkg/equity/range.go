@@ -0,0 +1,367 @@
+package equity
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/timothysugar/hand/pkg/hand"
+)
+
+var (
+	// ErrInvalidRange is returned when range notation cannot be parsed.
+	ErrInvalidRange = errors.New("invalid range")
+	// ErrEmptyRange is returned when no hand in a range is possible given the known cards.
+	ErrEmptyRange = errors.New("empty range")
+)
+
+// Combo is a pair of hole cards with the weight it is given in a range.
+type Combo struct {
+	Cards  [2]hand.Card
+	Weight float64
+}
+
+// Range is the set of hole cards a player may hold.
+type Range []Combo
+
+// Exact returns the range of just the given hole cards.
+func Exact(c1, c2 hand.Card) Range {
+	return Range{{Cards: [2]hand.Card{c1, c2}, Weight: 1}}
+}
+
+// ParseRange expands range notation into combos. The notation is a comma separated list of:
+//
+//   - pairs, such as "TT", and pairs of that rank or higher, such as "TT+"
+//   - suited or offsuit hands, such as "AKs" or "AKo", or both, such as "AK"
+//   - hands with kickers up to one below the higher rank, such as "A9s+"
+//   - spans of pairs or of kickers with the same higher rank, such as "55-22" or "A5s-A2s"
+//   - specific hole cards, such as "AsKs"
+//
+// Any entry may be given a weight from 0 to 1 with a suffix, such as "AKo:0.5", otherwise its
+// combos have a weight of 1. A combo given more than once takes its last weight.
+func ParseRange(notation string) (Range, error) {
+	var r Range
+	index := make(map[[2]hand.Card]int)
+	for _, entry := range strings.Split(notation, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		weight := 1.0
+		if i := strings.IndexByte(entry, ':'); i >= 0 {
+			w, err := strconv.ParseFloat(entry[i+1:], 64)
+			if err != nil || w < 0 || w > 1 {
+				return nil, fmt.Errorf("%w: weight of %q must be from 0 to 1", ErrInvalidRange, entry)
+			}
+			entry, weight = entry[:i], w
+		}
+		combos, err := parseEntry(entry)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range combos {
+			if i, ok := index[c]; ok {
+				r[i].Weight = weight
+				continue
+			}
+			index[c] = len(r)
+			r = append(r, Combo{Cards: c, Weight: weight})
+		}
+	}
+	return r, nil
+}
+
+// Without returns the combos in the range which do not use any of the given cards.
+func (r Range) Without(known []hand.Card) Range {
+	dead := make(map[hand.Card]bool)
+	for _, c := range known {
+		dead[c] = true
+	}
+	var out Range
+	for _, v := range r {
+		if !dead[v.Cards[0]] && !dead[v.Cards[1]] && v.Weight > 0 {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// CalculateRanges returns the equity of each range against the others. Each combination of hands
+// from the ranges which share no cards is dealt out with a likelihood in proportion to the product
+// of their weights. Every combination and board is enumerated if there are at most as many as the
+// number of samples, otherwise they are sampled at random. Only hold'em ranges are supported.
+func CalculateRanges(ranges []Range, opts Options) (Calculation, error) {
+	if len(ranges) < 2 {
+		return Calculation{}, ErrNotEnoughHands
+	}
+	if opts.Variant != hand.TexasHoldem {
+		return Calculation{}, fmt.Errorf("%w: ranges are of hold'em hands", ErrInvalidHand)
+	}
+	if len(opts.Board) > 5 {
+		return Calculation{}, fmt.Errorf("%w: %d cards", ErrInvalidBoard, len(opts.Board))
+	}
+	known := append(append([]hand.Card{}, opts.Board...), opts.Dead...)
+	deck, err := remaining(known)
+	if err != nil {
+		return Calculation{}, err
+	}
+	rs := make([]Range, len(ranges))
+	tuples := 1
+	for i, v := range ranges {
+		if rs[i] = v.Without(known); len(rs[i]) == 0 {
+			return Calculation{}, fmt.Errorf("%w: range %d", ErrEmptyRange, i)
+		}
+		tuples *= len(rs[i])
+	}
+
+	missing := 5 - len(opts.Board)
+	if left := len(deck) - 2*len(rs); left < missing {
+		return Calculation{}, fmt.Errorf("%w: %d cards remain to deal %d to the board", ErrNotEnoughCards, left, missing)
+	}
+
+	samples := opts.Samples
+	if samples <= 0 {
+		samples = DefaultSamples
+	}
+	e := newEvaluation(len(rs), opts)
+	hands := make([][]hand.Card, len(rs))
+	if tuples <= samples && tuples*choose(len(deck)-2*len(rs), missing) <= samples {
+		eachTuple(rs, func(combos []Combo) {
+			weight := 1.0
+			var used []hand.Card
+			for i, c := range combos {
+				hands[i] = []hand.Card{c.Cards[0], c.Cards[1]}
+				weight *= c.Weight
+				used = append(used, c.Cards[:]...)
+			}
+			d := without(deck, used)
+			e.enumerate(d, func() { e.evaluate(hands, weight) })
+		})
+		if e.boards == 0 {
+			return Calculation{}, fmt.Errorf("%w: every combination of hands shares a card", ErrEmptyRange)
+		}
+		return e.calculation(true), nil
+	}
+
+	r := rand.New(rand.NewSource(opts.Seed))
+	for i := 0; i < samples; i++ {
+		used, ok := sampleTuple(r, rs, hands)
+		if !ok {
+			return Calculation{}, fmt.Errorf("%w: hands cannot be dealt from the ranges without sharing a card", ErrEmptyRange)
+		}
+		e.sample(r, without(deck, used))
+		e.evaluate(hands, 1)
+	}
+	return e.calculation(false), nil
+}
+
+// maxAttempts is the number of times sampling a combination of hands from ranges is attempted
+// before they are assumed to always share a card.
+const maxAttempts = 1000
+
+// sampleTuple chooses a hand from each range in proportion to its weight, such that no two share a
+// card, and returns the cards used.
+func sampleTuple(r *rand.Rand, rs []Range, hands [][]hand.Card) ([]hand.Card, bool) {
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		used := make(map[hand.Card]bool)
+		var cards []hand.Card
+		ok := true
+		for i, rng := range rs {
+			c := rng.pick(r)
+			if used[c.Cards[0]] || used[c.Cards[1]] {
+				ok = false
+				break
+			}
+			used[c.Cards[0]], used[c.Cards[1]] = true, true
+			hands[i] = c.Cards[:]
+			cards = append(cards, c.Cards[:]...)
+		}
+		if ok {
+			return cards, true
+		}
+	}
+	return nil, false
+}
+
+// pick returns a combo from the range chosen in proportion to its weight.
+func (r Range) pick(rnd *rand.Rand) Combo {
+	total := 0.0
+	for _, v := range r {
+		total += v.Weight
+	}
+	x := rnd.Float64() * total
+	for _, v := range r {
+		if x -= v.Weight; x < 0 {
+			return v
+		}
+	}
+	return r[len(r)-1]
+}
+
+// without returns the cards of the deck other than those used.
+func without(deck []hand.Card, used []hand.Card) []hand.Card {
+	out := make([]hand.Card, 0, len(deck))
+	for _, c := range deck {
+		dead := false
+		for _, u := range used {
+			if c == u {
+				dead = true
+				break
+			}
+		}
+		if !dead {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// eachTuple calls fn with every combination of one combo from each range in which no two combos
+// share a card.
+func eachTuple(rs []Range, fn func([]Combo)) {
+	combos := make([]Combo, len(rs))
+	used := make(map[hand.Card]bool)
+	var walk func(int)
+	walk = func(i int) {
+		if i == len(rs) {
+			fn(combos)
+			return
+		}
+		for _, c := range rs[i] {
+			if used[c.Cards[0]] || used[c.Cards[1]] {
+				continue
+			}
+			used[c.Cards[0]], used[c.Cards[1]] = true, true
+			combos[i] = c
+			walk(i + 1)
+			used[c.Cards[0]], used[c.Cards[1]] = false, false
+		}
+	}
+	walk(0)
+}
+
+const rankChars = "23456789TJQKA"
+
+// parseEntry returns the hole cards described by a single entry of range notation.
+func parseEntry(entry string) ([][2]hand.Card, error) {
+	invalid := fmt.Errorf("%w: %q", ErrInvalidRange, entry)
+	if cs, err := hand.ParseCards(entry); err == nil {
+		if len(cs) != 2 || cs[0] == cs[1] {
+			return nil, invalid
+		}
+		return [][2]hand.Card{ordered(cs[0], cs[1])}, nil
+	}
+	if from, to, ok := strings.Cut(entry, "-"); ok {
+		h1, ok1 := parseHand(from)
+		h2, ok2 := parseHand(to)
+		if !ok1 || !ok2 || h1.suited != h2.suited || h1.plus || h2.plus {
+			return nil, invalid
+		}
+		var combos [][2]hand.Card
+		switch {
+		case h1.high == h1.low && h2.high == h2.low:
+			for r := minInt(h1.high, h2.high); r <= maxInt(h1.high, h2.high); r++ {
+				combos = append(combos, holeCards(r, r, "")...)
+			}
+		case h1.high == h2.high && h1.high != h1.low && h2.high != h2.low:
+			for k := minInt(h1.low, h2.low); k <= maxInt(h1.low, h2.low); k++ {
+				combos = append(combos, holeCards(h1.high, k, h1.suited)...)
+			}
+		default:
+			return nil, invalid
+		}
+		return combos, nil
+	}
+	h, ok := parseHand(entry)
+	if !ok {
+		return nil, invalid
+	}
+	if !h.plus {
+		return holeCards(h.high, h.low, h.suited), nil
+	}
+	var combos [][2]hand.Card
+	if h.high == h.low {
+		for r := h.high; r < len(rankChars); r++ {
+			combos = append(combos, holeCards(r, r, "")...)
+		}
+		return combos, nil
+	}
+	for k := h.low; k < h.high; k++ {
+		combos = append(combos, holeCards(h.high, k, h.suited)...)
+	}
+	return combos, nil
+}
+
+// rangeHand is a hand in range notation such as "AKs+", with ranks as indices of rankChars.
+type rangeHand struct {
+	high, low int
+	suited    string
+	plus      bool
+}
+
+func parseHand(s string) (rangeHand, bool) {
+	var h rangeHand
+	if strings.HasSuffix(s, "+") {
+		h.plus, s = true, strings.TrimSuffix(s, "+")
+	}
+	if len(s) == 3 && (s[2] == 's' || s[2] == 'o') {
+		h.suited, s = s[2:], s[:2]
+	}
+	if len(s) != 2 {
+		return h, false
+	}
+	h.high, h.low = strings.IndexByte(rankChars, s[0]), strings.IndexByte(rankChars, s[1])
+	if h.high < 0 || h.low < 0 || (h.high == h.low && h.suited != "") {
+		return h, false
+	}
+	if h.high < h.low {
+		h.high, h.low = h.low, h.high
+	}
+	return h, true
+}
+
+// holeCards returns every combination of hole cards with the given ranks, suited if "s", offsuit
+// if "o" or either if empty.
+func holeCards(high, low int, suited string) [][2]hand.Card {
+	suits := []hand.Suit{hand.Clubs, hand.Diamonds, hand.Hearts, hand.Spades}
+	var combos [][2]hand.Card
+	for i := 0; i < len(suits); i++ {
+		for j := 0; j < len(suits); j++ {
+			if high == low && j <= i {
+				continue
+			}
+			if (suited == "s" && i != j) || (suited == "o" && i == j) {
+				continue
+			}
+			c1 := hand.Card{Suit: suits[i], Rank: hand.Two + hand.Rank(high)}
+			c2 := hand.Card{Suit: suits[j], Rank: hand.Two + hand.Rank(low)}
+			combos = append(combos, [2]hand.Card{c1, c2})
+		}
+	}
+	return combos
+}
+
+// ordered returns the hole cards in the order given them by holeCards, with the higher rank first
+// or, for a pair, the lower suit, so that the same cards are the same combo however they are typed.
+func ordered(c1, c2 hand.Card) [2]hand.Card {
+	if c1.Rank < c2.Rank || (c1.Rank == c2.Rank && c1.Suit > c2.Suit) {
+		return [2]hand.Card{c2, c1}
+	}
+	return [2]hand.Card{c1, c2}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
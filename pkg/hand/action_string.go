@@ -14,11 +14,13 @@ func _() {
 	_ = x[Fold-3]
 	_ = x[Call-4]
 	_ = x[Raise-5]
+	_ = x[Bet-6]
+	_ = x[AllIn-7]
 }
 
-const _Action_name = "UndefinedBlindCheckFoldCallRaise"
+const _Action_name = "UndefinedBlindCheckFoldCallRaiseBetAllIn"
 
-var _Action_index = [...]uint8{0, 9, 14, 19, 23, 27, 32}
+var _Action_index = [...]uint8{0, 9, 14, 19, 23, 27, 32, 35, 40}
 
 func (i Action) String() string {
 	if i < 0 || i >= Action(len(_Action_index)-1) {
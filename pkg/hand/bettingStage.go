@@ -1,7 +1,5 @@
 package hand
 
-import "errors"
-
 type bettingStage struct {
 	initial       []*Player
 	plays         []Input
@@ -33,7 +31,7 @@ func (bs bettingStage) enter(h *Hand) error {
 }
 
 func (bs bettingStage) exit(h *Hand) error {
-	h.playFromDealer()
+	h.playFromFirst()
 	return nil
 }
 
@@ -53,8 +51,12 @@ func (bs bettingStage) handleInput(h *Hand, p *Player, inp Input) (stage, error)
 		err = h.check(p)
 	case Raise:
 		err = h.raise(p, inp.Chips)
+	case Bet:
+		err = h.bet(p, inp.Chips)
+	case AllIn:
+		err = h.allIn(p, inp.Chips)
 	default:
-		return nil, errors.New("unsupported input")
+		return nil, &ActionError{PlayerId: p.Id, Action: inp.Action, Street: h.stage.street(), Reason: "not a betting action"}
 	}
 
 	if err != nil {
@@ -62,31 +64,30 @@ func (bs bettingStage) handleInput(h *Hand, p *Player, inp Input) (stage, error)
 	}
 
 	bs.plays = append(bs.plays, inp)
-	if bs.allPlayed(h.pot) {
+	if bs.allPlayed(h) {
 		bs.exit(h)
-		return bs.makeNextStage(h.activePlayers()), nil
+		return bs.next(h), nil
 	}
 
 	return bs.makeCurrStage(bs), nil
 }
 
-func (bs bettingStage) allPlayed(pot pot) bool {
-	return (len(bs.plays) >= len(bs.initial) && !pot.outstandingStake())
+func (bs bettingStage) allPlayed(h *Hand) bool {
+	return (len(bs.plays) >= len(bs.initial) && !h.pot.outstandingStake(h.players))
+}
+
+// next returns the stage following this one, in which the players able to act are to bet.
+func (bs bettingStage) next(h *Hand) stage {
+	return bs.makeNextStage(h.actors())
 }
 
 func (bs bettingStage) validMoves(h *Hand) map[string][]Move {
-	pms := make(map[string][]Move)
-	mvs := make([]Move, 0)
 	plyr := h.nextToPlay
-	mvs = append(mvs, NewMove(Fold, RequiredBet{})) // fold
-	req := bs.requiredBet(h, plyr)
-	if req == 0 {
-		mvs = append(mvs, NewMove(Check, RequiredBet{}))      // check
-		mvs = append(mvs, NewMove(Raise, NewMinumumBet(req))) // raise
-	} else {
-		mvs = append(mvs, NewMove(Call, NewExactBet(req)))    // call
-		mvs = append(mvs, NewMove(Raise, NewMinumumBet(req))) // raise
-	}
-	pms[plyr.Id] = mvs
-	return pms
+	return map[string][]Move{plyr.Id: h.wager(plyr).moves(make([]Move, 0, 5))}
+}
+
+func (bs bettingStage) clone() stage {
+	c := bs
+	c.plays = append([]Input{}, bs.plays...)
+	return bs.makeCurrStage(c)
 }
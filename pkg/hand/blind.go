@@ -17,6 +17,14 @@ func (b blind) played() bool {
 	return b.contributed >= b.required
 }
 
+// capped returns the blind reduced to the chips of a player who cannot post all of it.
+func (b blind) capped(chips int) blind {
+	if chips < b.required {
+		b.required = chips
+	}
+	return b
+}
+
 func (b blind) play(value int) (*blind, error) {
 	if value != b.required {
 		return nil, errors.New("blind value played does not match required")
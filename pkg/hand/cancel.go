@@ -0,0 +1,86 @@
+package hand
+
+import "context"
+
+type cancelled struct {
+	at stage
+}
+
+// Cancel aborts the hand, such as on a misdeal. Every player's contributions to the pot are
+// returned to their stack and a cancelled result is sent on the channel returned by Begin, which
+// is then closed. The cancellation is recorded in the history with the reason given.
+func (h *Hand) Cancel(reason string) error {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	return h.cancel(reason)
+}
+
+func (h *Hand) cancel(reason string) error {
+	if h.isFinished() {
+		return ErrHandFinished
+	}
+	h.pot.refund(h.dealt)
+	h.stage = cancelled{h.stage}
+	h.history = append(h.history, Entry{
+		Seq:    len(h.history) + 1,
+		Street: h.stage.street(),
+		Note:   "cancelled: " + reason,
+	})
+	h.log = nil
+	h.record()
+	h.startTurn()
+	h.finish(FinishedHand{cancelled: true, reason: reason})
+	return nil
+}
+
+// cancelOnDone cancels the hand if the context is done before the hand is finished.
+func (h *Hand) cancelOnDone(ctx context.Context) {
+	if ctx.Done() == nil {
+		return
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			h.Cancel(ctx.Err().Error())
+		case <-h.done:
+		}
+	}()
+}
+
+func (h *Hand) isFinished() bool {
+	switch h.stage.(type) {
+	case won, cancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+func (curr cancelled) enter(h *Hand) error {
+	return nil
+}
+
+func (curr cancelled) exit(h *Hand) error {
+	return nil
+}
+
+func (curr cancelled) handleInput(h *Hand, p *Player, inp Input) (stage, error) {
+	return nil, ErrHandFinished
+}
+
+func (curr cancelled) validMoves(h *Hand) map[string][]Move {
+	return make(map[string][]Move)
+}
+
+func (curr cancelled) requiredBet(h *Hand, p *Player) int {
+	return 0
+}
+
+func (curr cancelled) street() Street {
+	return curr.at.street()
+}
+
+func (curr cancelled) clone() stage {
+	return curr
+}
@@ -1,6 +1,264 @@
 package hand
 
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Card is a card of a standard deck. The zero value is a card which is not known, such as one dealt
+// face down.
+//
+// A card is written in short notation as its rank followed by its suit, such as "As" for the ace of
+// spades or "Td" for the ten of diamonds. It is marshalled to JSON with the long names of its rank
+// and suit, and may be unmarshalled from them or from short notation.
 type Card struct {
-	Suit string
-	Rank string
+	Suit Suit
+	Rank Rank
+}
+
+// Suit is the suit of a card, or 0 if it is not known.
+type Suit int
+
+const (
+	Clubs Suit = iota + 1
+	Diamonds
+	Hearts
+	Spades
+)
+
+// Rank is the rank of a card, numbered from 2 for a two to 14 for an ace, or 0 if it is not known.
+type Rank int
+
+const (
+	Two Rank = iota + 2
+	Three
+	Four
+	Five
+	Six
+	Seven
+	Eight
+	Nine
+	Ten
+	Jack
+	Queen
+	King
+	Ace
+)
+
+var (
+	suitNames   = [...]string{Clubs: "Clubs", Diamonds: "Diamonds", Hearts: "Hearts", Spades: "Spades"}
+	suitLetters = [...]string{Clubs: "c", Diamonds: "d", Hearts: "h", Spades: "s"}
+	suitSymbols = [...]string{Clubs: "♣", Diamonds: "♦", Hearts: "♥", Spades: "♠"}
+	rankNames   = [...]string{Two: "2", Three: "3", Four: "4", Five: "5", Six: "6", Seven: "7", Eight: "8",
+		Nine: "9", Ten: "10", Jack: "Jack", Queen: "Queen", King: "King", Ace: "Ace"}
+	rankLetters = [...]string{Two: "2", Three: "3", Four: "4", Five: "5", Six: "6", Seven: "7", Eight: "8",
+		Nine: "9", Ten: "T", Jack: "J", Queen: "Q", King: "K", Ace: "A"}
+)
+
+// ParseCard parses a card in short notation, such as "As" or "Td". A ten may also be written "10",
+// and a suit as its symbol, such as "A♠". An error wrapping ErrUnknownCard is returned if the card
+// is not one of a standard deck.
+func ParseCard(s string) (Card, error) {
+	c, n, err := parseCard(s)
+	if err == nil && n < len(s) {
+		err = fmt.Errorf("%w: %q", ErrUnknownCard, s)
+	}
+	return c, err
+}
+
+// ParseCards parses a list of cards in short notation, which may be written together, such as
+// "AsKd7c", or separated by spaces or commas.
+func ParseCards(s string) ([]Card, error) {
+	var cs []Card
+	for {
+		s = strings.TrimLeft(s, " ,")
+		if s == "" {
+			return cs, nil
+		}
+		c, n, err := parseCard(s)
+		if err != nil {
+			return nil, err
+		}
+		cs = append(cs, c)
+		s = s[n:]
+	}
+}
+
+// parseCard parses the card at the start of the string, returning the number of bytes it took.
+func parseCard(s string) (Card, int, error) {
+	invalid := fmt.Errorf("%w: %q", ErrUnknownCard, s)
+	n := 1
+	if strings.HasPrefix(s, "10") {
+		n = 2
+	}
+	if len(s) <= n {
+		return Card{}, 0, invalid
+	}
+	r, err := ParseRank(s[:n])
+	if err != nil {
+		return Card{}, 0, invalid
+	}
+	_, size := utf8.DecodeRuneInString(s[n:])
+	suit, err := ParseSuit(s[n : n+size])
+	if err != nil {
+		return Card{}, 0, invalid
+	}
+	return Card{Suit: suit, Rank: r}, n + size, nil
+}
+
+// String returns the card in short notation, or "??" if it is not known.
+func (c Card) String() string {
+	if !c.Valid() {
+		return "??"
+	}
+	return c.Rank.String() + c.Suit.String()
+}
+
+// Unicode returns the card in short notation with the symbol of its suit, such as "A♠", or "??" if
+// it is not known.
+func (c Card) Unicode() string {
+	if !c.Valid() {
+		return "??"
+	}
+	return c.Rank.String() + c.Suit.Unicode()
+}
+
+// Valid reports whether the card is one of a standard deck.
+func (c Card) Valid() bool {
+	return c.Suit.Valid() && c.Rank.Valid()
+}
+
+// UnmarshalJSON reads a card from an object with the names of its suit and rank, in long or short
+// form, or from a string in short notation.
+func (c *Card) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		card, err := ParseCard(s)
+		if err != nil {
+			return err
+		}
+		*c = card
+		return nil
+	}
+	var fields struct {
+		Suit Suit
+		Rank Rank
+	}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+	*c = Card(fields)
+	return nil
+}
+
+// ParseSuit parses a suit from its name, such as "Spades", its letter, such as "s", or its symbol,
+// such as "♠", ignoring case.
+func ParseSuit(s string) (Suit, error) {
+	for v := Clubs; v <= Spades; v++ {
+		if strings.EqualFold(s, suitNames[v]) || strings.EqualFold(s, suitLetters[v]) || s == suitSymbols[v] {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: suit %q", ErrUnknownCard, s)
+}
+
+// String returns the suit's letter, or "?" if it is not known.
+func (s Suit) String() string {
+	if !s.Valid() {
+		return "?"
+	}
+	return suitLetters[s]
+}
+
+// Name returns the suit's name, such as "Spades", or "" if it is not known.
+func (s Suit) Name() string {
+	if !s.Valid() {
+		return ""
+	}
+	return suitNames[s]
+}
+
+// Unicode returns the suit's symbol, such as "♠", or "?" if it is not known.
+func (s Suit) Unicode() string {
+	if !s.Valid() {
+		return "?"
+	}
+	return suitSymbols[s]
+}
+
+// Valid reports whether the suit is one of the four suits.
+func (s Suit) Valid() bool {
+	return s >= Clubs && s <= Spades
+}
+
+// MarshalText returns the suit's name, which is empty if it is not known.
+func (s Suit) MarshalText() ([]byte, error) {
+	return []byte(s.Name()), nil
+}
+
+// UnmarshalText parses a suit as by ParseSuit, or a suit which is not known from an empty string.
+func (s *Suit) UnmarshalText(b []byte) error {
+	if len(b) == 0 {
+		*s = 0
+		return nil
+	}
+	v, err := ParseSuit(string(b))
+	if err != nil {
+		return err
+	}
+	*s = v
+	return nil
+}
+
+// ParseRank parses a rank from its name, such as "Ace" or "10", or its letter, such as "A" or "T",
+// ignoring case.
+func ParseRank(s string) (Rank, error) {
+	for v := Two; v <= Ace; v++ {
+		if strings.EqualFold(s, rankNames[v]) || strings.EqualFold(s, rankLetters[v]) {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: rank %q", ErrUnknownCard, s)
+}
+
+// String returns the rank's letter or number, such as "A" or "9", or "?" if it is not known.
+func (r Rank) String() string {
+	if !r.Valid() {
+		return "?"
+	}
+	return rankLetters[r]
+}
+
+// Name returns the rank's name, such as "Ace" or "10", or "" if it is not known.
+func (r Rank) Name() string {
+	if !r.Valid() {
+		return ""
+	}
+	return rankNames[r]
+}
+
+// Valid reports whether the rank is one of the thirteen ranks.
+func (r Rank) Valid() bool {
+	return r >= Two && r <= Ace
+}
+
+// MarshalText returns the rank's name, which is empty if it is not known.
+func (r Rank) MarshalText() ([]byte, error) {
+	return []byte(r.Name()), nil
+}
+
+// UnmarshalText parses a rank as by ParseRank, or a rank which is not known from an empty string.
+func (r *Rank) UnmarshalText(b []byte) error {
+	if len(b) == 0 {
+		*r = 0
+		return nil
+	}
+	v, err := ParseRank(string(b))
+	if err != nil {
+		return err
+	}
+	*r = v
+	return nil
 }
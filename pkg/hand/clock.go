@@ -0,0 +1,25 @@
+package hand
+
+import "time"
+
+// Clock is the source of time used by a hand to enforce time limits. It allows tests and
+// simulations to control the passing of time.
+type Clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a pending call created by a Clock.
+type Timer interface {
+	Stop() bool
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
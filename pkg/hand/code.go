@@ -0,0 +1,56 @@
+package hand
+
+import "fmt"
+
+// CardCode is a compact encoding of a card of a standard deck, numbering the cards from 0 to 51 in
+// the order of FullDeck. A card's code is the index of its suit times 13 plus the index of its
+// rank, from 0 for a two to 12 for an ace.
+type CardCode uint8
+
+// fullDeck is every card in the order of FullDeck, indexed by code.
+var fullDeck = FullDeck()
+
+// Code returns the code of the card, reporting false if the card is not known.
+func (c Card) Code() (CardCode, bool) {
+	if !c.Valid() {
+		return 0, false
+	}
+	return CardCode(int(c.Suit-Clubs)*len(ranks) + int(c.Rank-Two)), true
+}
+
+// Card returns the card with the code.
+func (c CardCode) Card() Card {
+	return fullDeck[c]
+}
+
+// Rank returns the rank of the card.
+func (c CardCode) Rank() Rank {
+	return Two + Rank(int(c)%len(ranks))
+}
+
+// Suit returns the suit of the card.
+func (c CardCode) Suit() Suit {
+	return Clubs + Suit(int(c)/len(ranks))
+}
+
+// EncodeCards returns the codes of the cards. An error is returned if any of them is not known.
+func EncodeCards(cs []Card) ([]CardCode, error) {
+	codes := make([]CardCode, len(cs))
+	for i, c := range cs {
+		code, ok := c.Code()
+		if !ok {
+			return nil, fmt.Errorf("%w: %v", ErrUnknownCard, c)
+		}
+		codes[i] = code
+	}
+	return codes, nil
+}
+
+// DecodeCards returns the cards with the codes.
+func DecodeCards(cs []CardCode) []Card {
+	cards := make([]Card, len(cs))
+	for i, c := range cs {
+		cards[i] = c.Card()
+	}
+	return cards
+}
@@ -0,0 +1,37 @@
+package hand
+
+import "math/rand"
+
+var (
+	suits = []Suit{Clubs, Diamonds, Hearts, Spades}
+	ranks = []Rank{Two, Three, Four, Five, Six, Seven, Eight, Nine, Ten, Jack, Queen, King, Ace}
+)
+
+type deck struct {
+	cards []Card
+}
+
+// FullDeck returns the 52 cards of a deck in order of suit then rank.
+func FullDeck() []Card {
+	cards := make([]Card, 0, len(suits)*len(ranks))
+	for _, s := range suits {
+		for _, v := range ranks {
+			cards = append(cards, Card{Suit: s, Rank: v})
+		}
+	}
+	return cards
+}
+
+// newDeck returns a full deck shuffled by the given source.
+func newDeck(r *rand.Rand) *deck {
+	cards := FullDeck()
+	r.Shuffle(len(cards), func(i, j int) { cards[i], cards[j] = cards[j], cards[i] })
+	return &deck{cards}
+}
+
+// deal removes and returns the given number of cards from the top of the deck.
+func (d *deck) deal(num int) []Card {
+	cs := append([]Card{}, d.cards[:num]...)
+	d.cards = d.cards[num:]
+	return cs
+}
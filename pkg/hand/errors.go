@@ -0,0 +1,95 @@
+package hand
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotEnoughPlayers is returned when a hand cannot be dealt to fewer than two players.
+	ErrNotEnoughPlayers = errors.New("hand requires at least 2 players")
+	// ErrInvalidRules is wrapped by the errors describing invalid rules for a hand.
+	ErrInvalidRules = errors.New("invalid rules")
+	// ErrHandActive is returned for operations which are only allowed before the hand begins.
+	ErrHandActive = errors.New("hand already active")
+	// ErrHandFinished is returned for moves played after the hand is won.
+	ErrHandFinished = errors.New("hand finished")
+	// ErrPlayerNotFound is returned when the player is not in the hand.
+	ErrPlayerNotFound = errors.New("player not found in hand")
+	// ErrDuplicatePlayer is returned when joining a hand with the name of a player already seated.
+	ErrDuplicatePlayer = errors.New("duplicate player name")
+	// ErrNoChips is returned when joining a hand without chips.
+	ErrNoChips = errors.New("player must join with chips")
+	// ErrInvalidSeat is returned when a seat does not exist at the table.
+	ErrInvalidSeat = errors.New("invalid seat")
+	// ErrSeatTaken is returned when sitting in a seat which is already occupied.
+	ErrSeatTaken = errors.New("seat taken")
+	// ErrTableFull is returned when sitting at a table with no empty seats.
+	ErrTableFull = errors.New("table full")
+	// ErrNotBroadcast is returned when requesting a broadcast of a hand without a broadcast delay.
+	ErrNotBroadcast = errors.New("hand is not being broadcast")
+	// ErrUnknownCard is returned when a card is not one of a standard deck.
+	ErrUnknownCard = errors.New("unknown card")
+
+	// ErrOutOfTurn is returned when a player plays when it is not their turn.
+	ErrOutOfTurn = errors.New("player is not next to play")
+	// ErrStaleDecision is returned when the hand moved on while a strategy decided on its move.
+	ErrStaleDecision = errors.New("hand moved on while deciding")
+	// ErrInvalidAction is returned when an action is not allowed at this point in the hand.
+	ErrInvalidAction = errors.New("invalid action")
+	// ErrInvalidBet is returned when the chips played are not allowed for the action.
+	ErrInvalidBet = errors.New("invalid bet")
+)
+
+// OutOfTurnError describes a player attempting to play when it is not their turn.
+type OutOfTurnError struct {
+	PlayerId     string
+	NextToPlayId string
+}
+
+func (e *OutOfTurnError) Error() string {
+	return fmt.Sprintf("%s is next to play but %s attempted", e.NextToPlayId, e.PlayerId)
+}
+
+func (e *OutOfTurnError) Is(target error) bool {
+	return target == ErrOutOfTurn
+}
+
+// ActionError describes an action a player is not allowed to take.
+type ActionError struct {
+	PlayerId string
+	Action   Action
+	Street   Street
+	Reason   string
+}
+
+func (e *ActionError) Error() string {
+	return fmt.Sprintf("%v by %s is not allowed on the %v: %s", e.Action, e.PlayerId, e.Street, e.Reason)
+}
+
+func (e *ActionError) Is(target error) bool {
+	return target == ErrInvalidAction
+}
+
+// BetError describes a number of chips played which is not within the range allowed for the action.
+type BetError struct {
+	PlayerId string
+	Action   Action
+	Chips    int
+	Allowed  RequiredBet
+}
+
+func (e *BetError) Error() string {
+	if e.Allowed.Minimum == e.Allowed.Maximum {
+		return fmt.Sprintf("%v of %d by %s must be %d", e.Action, e.Chips, e.PlayerId, e.Allowed.Minimum)
+	}
+	return fmt.Sprintf("%v of %d by %s must be between %d and %d", e.Action, e.Chips, e.PlayerId, e.Allowed.Minimum, e.Allowed.Maximum)
+}
+
+func (e *BetError) Is(target error) bool {
+	return target == ErrInvalidBet
+}
+
+func invalidRules(format string, a ...any) error {
+	return fmt.Errorf("%w: "+format, append([]any{ErrInvalidRules}, a...)...)
+}
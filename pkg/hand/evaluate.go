@@ -0,0 +1,191 @@
+//go:generate stringer -type=HandCategory
+package hand
+
+import "sort"
+
+// HandCategory is the category of a five card poker hand.
+type HandCategory int
+
+const (
+	HighCard HandCategory = iota
+	Pair
+	TwoPair
+	ThreeOfAKind
+	Straight
+	Flush
+	FullHouse
+	FourOfAKind
+	StraightFlush
+)
+
+// HandRank is the strength of a five card poker hand. A hand beats another of lower rank and ties
+// with one of equal rank. The zero value is weaker than every hand.
+type HandRank uint32
+
+// Category returns the category of the hand.
+func (r HandRank) Category() HandCategory {
+	return HandCategory(r>>20) - 1
+}
+
+// Evaluate returns the rank of the best five card hand that can be made from the given cards, or 0
+// if fewer than five cards are known.
+func Evaluate(cards []Card) HandRank {
+	var codes [7]CardCode
+	n := 0
+	for _, c := range cards {
+		code, ok := c.Code()
+		if !ok && rankValue(c) < 0 {
+			continue
+		}
+		if !ok || n == len(codes) {
+			return evaluateCombinations(cards)
+		}
+		codes[n] = code
+		n++
+	}
+	return EvaluateCodes(codes[:n])
+}
+
+// evaluateCombinations returns the rank of the best hand made by any five of the known cards, by
+// ranking every combination of them. It is much slower than the lookup tables, but ranks as known
+// the cards of any suit and any number of cards.
+func evaluateCombinations(cards []Card) HandRank {
+	var known []Card
+	for _, c := range cards {
+		if rankValue(c) >= 0 {
+			known = append(known, c)
+		}
+	}
+	if len(known) < 5 {
+		return 0
+	}
+	var best HandRank
+	Combinations(len(known), 5, func(idx []int) {
+		var five [5]Card
+		for i, v := range idx {
+			five[i] = known[v]
+		}
+		if r := evaluateFive(five); r > best {
+			best = r
+		}
+	})
+	return best
+}
+
+// EvaluateOmaha returns the rank of the best hand that can be made from exactly two of the hole
+// cards and three of the board, or 0 if there are not enough known cards.
+func EvaluateOmaha(hole []Card, board []Card) HandRank {
+	var best HandRank
+	Combinations(len(hole), 2, func(h []int) {
+		Combinations(len(board), 3, func(b []int) {
+			five := [5]Card{hole[h[0]], hole[h[1]], board[b[0]], board[b[1]], board[b[2]]}
+			for _, c := range five {
+				if rankValue(c) < 0 {
+					return
+				}
+			}
+			if r := evaluateFive(five); r > best {
+				best = r
+			}
+		})
+	})
+	return best
+}
+
+// evaluateFive returns the rank of five known cards. The category is held above the ranks of the
+// cards that break ties within it, in order of significance.
+func evaluateFive(cs [5]Card) HandRank {
+	var counts [13]int
+	flush := true
+	for _, c := range cs {
+		counts[rankValue(c)]++
+		flush = flush && c.Suit == cs[0].Suit
+	}
+
+	// ranks in order of the size of their group then the rank itself, so that for example a full
+	// house is ranked by its three of a kind then its pair
+	var ranks []int
+	for r := 12; r >= 0; r-- {
+		if counts[r] > 0 {
+			ranks = append(ranks, r)
+		}
+	}
+	sort.SliceStable(ranks, func(i, j int) bool { return counts[ranks[i]] > counts[ranks[j]] })
+
+	straight, high := false, 0
+	if len(ranks) == 5 {
+		switch {
+		case ranks[0]-ranks[4] == 4:
+			straight, high = true, ranks[0]
+		case ranks[0] == 12 && ranks[1] == 3:
+			// the wheel, in which the ace plays low
+			straight, high = true, 3
+		}
+	}
+
+	var category HandCategory
+	switch {
+	case straight && flush:
+		category = StraightFlush
+	case counts[ranks[0]] == 4:
+		category = FourOfAKind
+	case counts[ranks[0]] == 3 && counts[ranks[1]] == 2:
+		category = FullHouse
+	case flush:
+		category = Flush
+	case straight:
+		category = Straight
+	case counts[ranks[0]] == 3:
+		category = ThreeOfAKind
+	case counts[ranks[0]] == 2 && counts[ranks[1]] == 2:
+		category = TwoPair
+	case counts[ranks[0]] == 2:
+		category = Pair
+	default:
+		category = HighCard
+	}
+	if straight {
+		ranks = []int{high}
+	}
+
+	r := HandRank(category+1) << 20
+	for i, v := range ranks {
+		r |= HandRank(v+1) << (16 - 4*i)
+	}
+	return r
+}
+
+// rankValue returns the value of the card's rank from 0 for a two to 12 for an ace, or -1 if the
+// card is not known.
+func rankValue(c Card) int {
+	if !c.Rank.Valid() {
+		return -1
+	}
+	return int(c.Rank - Two)
+}
+
+// Combinations calls fn with the indices of each combination of k of n items in lexicographic
+// order, and not at all if k exceeds n. The indices passed to fn are reused between calls.
+func Combinations(n, k int, fn func([]int)) {
+	if k > n {
+		return
+	}
+	idx := make([]int, k)
+	for i := range idx {
+		idx[i] = i
+	}
+	for {
+		fn(idx)
+		i := k - 1
+		for i >= 0 && idx[i] == n-k+i {
+			i--
+		}
+		if i < 0 {
+			return
+		}
+		idx[i]++
+		for j := i + 1; j < k; j++ {
+			idx[j] = idx[j-1] + 1
+		}
+	}
+}
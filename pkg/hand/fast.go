@@ -0,0 +1,522 @@
+package hand
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// FastHand plays hands between strategies many times faster than a Hand, for simulations which
+// play millions of them. It keeps no history, takes no locks and reuses its memory from one hand
+// to the next, so that a hand allocates nothing beyond what its strategies do. Given the same rules
+// and source, it deals the same cards as a Hand and the players win the same chips.
+//
+// The views given to strategies share memory with the hand, so must not be kept or changed. A
+// FastHand is not safe for concurrent use.
+type FastHand struct {
+	rules  Rules
+	rand   *rand.Rand
+	blinds []int
+	bb     int
+	n      int
+	ids    []string
+
+	deck  [52]CardCode
+	top   int
+	hole  [][2]CardCode
+	board [5]CardCode
+	cards int
+
+	stacks   []int
+	contribs []int
+	base     []int
+	dead     int
+	folded   []bool
+	allIns   []bool
+	active   int
+
+	street    Street
+	next      int
+	initial   int
+	plays     int
+	lastRaise int
+	finished  bool
+
+	start   []int
+	net     []int
+	invalid []int
+	ranks   []HandRank
+	stakes  []int
+	amounts []int
+
+	self       [2]Card
+	boardCards [5]Card
+	opponents  []SeatView
+	moves      [5]Move
+}
+
+// NewFastHand creates a fast hand for the given number of players, played according to the rules.
+// Hands are dealt from a deck shuffled by the rules' source, or one seeded with 1 if it is nil.
+// Time limits are ignored, and hands of Omaha are not supported.
+func NewFastHand(rules Rules, players int) (*FastHand, error) {
+	if players < 2 {
+		return nil, ErrNotEnoughPlayers
+	}
+	if err := rules.Validate(players); err != nil {
+		return nil, err
+	}
+	if rules.Variant != TexasHoldem {
+		return nil, invalidRules("fast hands can only deal hold'em")
+	}
+	if 2*players+5 > len(fullDeck) {
+		return nil, invalidRules("too many players to deal %d to", players)
+	}
+	r := rules.Rand
+	if r == nil {
+		r = rand.New(rand.NewSource(1))
+	}
+	f := &FastHand{
+		rules:     rules,
+		rand:      r,
+		blinds:    rules.positionalBlinds(),
+		bb:        rules.bigBlind(),
+		n:         players,
+		ids:       make([]string, players),
+		hole:      make([][2]CardCode, players),
+		stacks:    make([]int, players),
+		contribs:  make([]int, players),
+		base:      make([]int, players),
+		folded:    make([]bool, players),
+		allIns:    make([]bool, players),
+		start:     make([]int, players),
+		net:       make([]int, players),
+		invalid:   make([]int, players),
+		ranks:     make([]HandRank, players),
+		stakes:    make([]int, 0, players+1),
+		amounts:   make([]int, 0, players+1),
+		opponents: make([]SeatView, 0, players-1),
+	}
+	for i := range f.ids {
+		f.ids[i] = fmt.Sprint(i)
+	}
+	return f, nil
+}
+
+// Seed seeds the source of the deck, so that the next hand is dealt as by a Hand whose rules have
+// a source newly seeded with the same value.
+func (f *FastHand) Seed(seed int64) {
+	f.rand.Seed(seed)
+}
+
+// Play deals a hand to players with the given stacks, in order from the dealer, and plays it with
+// their strategies. Blinds are posted for the players, who are identified to the strategies by
+// their position from the dealer. A decision which is not a valid move is played as a fold.
+//
+// The chips won by each player are returned, which are negative if chips were lost. The returned
+// slice is reused by the next hand.
+func (f *FastHand) Play(stacks []int, bots []Strategy) ([]int, error) {
+	if len(stacks) != f.n || len(bots) != f.n {
+		return nil, fmt.Errorf("%d stacks and %d strategies cannot play a hand of %d players", len(stacks), len(bots), f.n)
+	}
+	for _, v := range stacks {
+		if v <= 0 {
+			return nil, fmt.Errorf("%w: stack of %d", ErrNoChips, v)
+		}
+	}
+	f.reset(stacks)
+	f.deal()
+	if f.rules.Ante > 0 {
+		for i := range f.stacks {
+			ante := min(f.rules.Ante, f.stacks[i])
+			f.stacks[i] -= ante
+			f.dead += ante
+		}
+	}
+	if len(f.blinds) > 0 {
+		for i, v := range f.blinds {
+			f.add(i, min(v, f.stacks[i]))
+		}
+		f.street = Flop
+		if f.rules.PreflopBetting {
+			f.street = Preflop
+		}
+		f.dealBoard()
+		f.startRound()
+		f.playFrom(len(f.blinds) % f.n)
+		f.runOut()
+	} else {
+		// without blinds the betting opens on the first street, before any cards are dealt to it
+		f.street = Flop
+		if f.rules.PreflopBetting {
+			f.street = Preflop
+		}
+		f.startRound()
+		f.playFrom(0)
+		f.runOut()
+	}
+
+	for !f.finished {
+		seat := f.next
+		moves := f.validMoves(seat)
+		inp := bots[seat].Decide(f.view(seat, moves), moves)
+		if !f.play(seat, inp) {
+			f.invalid[seat]++
+			f.play(seat, Input{Action: Fold})
+		}
+	}
+	for i := range f.net {
+		f.net[i] -= f.start[i] - f.stacks[i]
+	}
+	return f.net, nil
+}
+
+// Invalid returns the number of decisions of the player in the given position in the last hand
+// which were not valid moves.
+func (f *FastHand) Invalid(position int) int {
+	return f.invalid[position]
+}
+
+func (f *FastHand) reset(stacks []int) {
+	copy(f.start, stacks)
+	copy(f.stacks, stacks)
+	for i := 0; i < f.n; i++ {
+		f.contribs[i] = 0
+		f.base[i] = 0
+		f.folded[i] = false
+		f.net[i] = 0
+		f.invalid[i] = 0
+	}
+	f.dead = 0
+	f.active = f.n
+	f.cards = 0
+	f.top = 0
+	f.plays = 0
+	f.lastRaise = f.bb
+	f.finished = false
+}
+
+// deal shuffles the deck and deals the hole cards, in the same order as a Hand.
+func (f *FastHand) deal() {
+	for i := range f.deck {
+		f.deck[i] = CardCode(i)
+	}
+	f.rand.Shuffle(len(f.deck), func(i, j int) { f.deck[i], f.deck[j] = f.deck[j], f.deck[i] })
+	for i := range f.hole {
+		f.hole[i] = [2]CardCode{f.deck[f.top], f.deck[f.top+1]}
+		f.top += 2
+	}
+}
+
+// dealBoard deals the board up to the cards of the current street.
+func (f *FastHand) dealBoard() {
+	want := [...]int{Preflop: 0, Flop: 3, Turn: 4, River: 5}[f.street]
+	for f.cards < want {
+		f.board[f.cards] = f.deck[f.top]
+		f.boardCards[f.cards] = f.deck[f.top].Card()
+		f.cards++
+		f.top++
+	}
+}
+
+// startRound starts a round of betting between the players now able to act.
+func (f *FastHand) startRound() {
+	f.initial, _ = f.actors()
+	f.plays = 0
+}
+
+// advance moves the hand on to the next street, or the showdown after the river.
+func (f *FastHand) advance() {
+	if f.street == River {
+		f.finish()
+		return
+	}
+	f.street++
+	f.dealBoard()
+	f.lastRaise = 0
+	copy(f.base, f.contribs)
+	f.startRound()
+}
+
+// runOut deals the remaining streets without betting while fewer than two players are able to act
+// and none of them has a stake to call.
+func (f *FastHand) runOut() {
+	for !f.finished {
+		n, first := f.actors()
+		if n > 1 || (n == 1 && f.required(first) > 0) {
+			return
+		}
+		f.advance()
+	}
+}
+
+// play plays the input for the player, reporting whether it was a valid move. Nothing is changed
+// by an input which is not valid.
+func (f *FastHand) play(seat int, inp Input) bool {
+	bet := f.currentBet()
+	stack := f.stacks[seat]
+	switch inp.Action {
+	case Fold:
+		if f.active == 1 {
+			return false
+		}
+		f.folded[seat] = true
+		f.active--
+		if f.active == 1 {
+			f.finish()
+			return true
+		}
+	case Call:
+		f.add(seat, min(f.required(seat), stack))
+	case Check:
+		if f.required(seat) != 0 {
+			return false
+		}
+	case Raise:
+		w := f.wager(seat)
+		to := w.raiseTo(inp.Chips)
+		if r := w.raiseRange(); to < r.Minimum || to > r.Maximum {
+			return false
+		}
+		f.add(seat, to-w.committed)
+	case Bet:
+		if r := f.wager(seat).betRange(); bet > 0 || inp.Chips < r.Minimum || inp.Chips > r.Maximum {
+			return false
+		}
+		f.add(seat, inp.Chips)
+	case AllIn:
+		if stack <= 0 || (inp.Chips != 0 && inp.Chips != stack) {
+			return false
+		}
+		f.add(seat, stack)
+	default:
+		return false
+	}
+
+	f.plays++
+	if curr := f.currentBet(); curr-bet > f.lastRaise {
+		f.lastRaise = curr - bet
+	}
+	if f.plays >= f.initial && !f.outstandingStake() {
+		f.playFromFirst()
+		f.advance()
+		f.runOut()
+		return true
+	}
+	f.nextMove()
+	return true
+}
+
+func (f *FastHand) add(seat int, chips int) {
+	f.stacks[seat] -= chips
+	f.contribs[seat] += chips
+}
+
+// playFromFirst passes play to the first player to act on a street after the first betting round.
+func (f *FastHand) playFromFirst() {
+	if f.n == 2 && len(f.blinds) == 2 {
+		f.playFrom(1)
+		return
+	}
+	f.playFrom(0)
+}
+
+// playFrom passes play to the player, or the next after them who is able to act.
+func (f *FastHand) playFrom(seat int) {
+	f.next = seat
+	if !f.canAct(seat) {
+		f.nextMove()
+	}
+}
+
+// nextMove passes play to the next player in order from the dealer who is able to act.
+func (f *FastHand) nextMove() {
+	for i := 1; i <= f.n; i++ {
+		if seat := (f.next + i) % f.n; f.canAct(seat) {
+			f.next = seat
+			return
+		}
+	}
+}
+
+func (f *FastHand) canAct(seat int) bool {
+	return !f.folded[seat] && !f.allIn(seat)
+}
+
+func (f *FastHand) allIn(seat int) bool {
+	return allIn(f.stacks[seat], f.start[seat]-f.stacks[seat])
+}
+
+// actors returns the number of players able to act and the first of them from the dealer.
+func (f *FastHand) actors() (int, int) {
+	n, first := 0, -1
+	for i := 0; i < f.n; i++ {
+		if f.canAct(i) {
+			if n == 0 {
+				first = i
+			}
+			n++
+		}
+	}
+	return n, first
+}
+
+func (f *FastHand) total() int {
+	total := f.dead
+	for _, v := range f.contribs {
+		total += v
+	}
+	return total
+}
+
+func (f *FastHand) required(seat int) int {
+	max := 0
+	for _, v := range f.contribs {
+		if v > max {
+			max = v
+		}
+	}
+	return max - f.contribs[seat]
+}
+
+func (f *FastHand) committed(seat int) int {
+	return f.contribs[seat] - f.base[seat]
+}
+
+func (f *FastHand) currentBet() int {
+	max := 0
+	for i := range f.contribs {
+		if c := f.committed(i); c > max {
+			max = c
+		}
+	}
+	return max
+}
+
+// outstandingStake reports whether any of the active players who are not all-in has staked less
+// than another active player.
+func (f *FastHand) outstandingStake() bool {
+	max := 0
+	for i, v := range f.contribs {
+		if !f.folded[i] && v > max {
+			max = v
+		}
+	}
+	for i, v := range f.contribs {
+		if !f.folded[i] && !f.allIn(i) && v < max {
+			return true
+		}
+	}
+	return false
+}
+
+// wager returns the betting on this street as it stands for the player.
+func (f *FastHand) wager(seat int) wager {
+	return wager{
+		limit:     f.rules.Limit,
+		raises:    f.rules.Raises,
+		bigBlind:  f.bb,
+		street:    f.street,
+		current:   f.currentBet(),
+		lastRaise: f.lastRaise,
+		committed: f.committed(seat),
+		required:  f.required(seat),
+		chips:     f.stacks[seat],
+		pot:       f.total(),
+	}
+}
+
+func (f *FastHand) validMoves(seat int) []Move {
+	return f.wager(seat).moves(f.moves[:0])
+}
+
+// view returns the hand as seen by the player, in memory reused by the next view.
+func (f *FastHand) view(seat int, moves []Move) View {
+	opponents := f.opponents[:0]
+	for i := 0; i < f.n; i++ {
+		if i != seat {
+			sv := f.seatView(i)
+			sv.FaceDown = 2
+			opponents = append(opponents, sv)
+		}
+	}
+	self := f.seatView(seat)
+	f.self = [2]Card{f.hole[seat][0].Card(), f.hole[seat][1].Card()}
+	self.Cards = f.self[:]
+	return View{
+		Self:      self,
+		Opponents: opponents,
+		Board:     f.boardCards[:f.cards],
+		Pot:       f.total(),
+		Street:    f.street,
+		Moves:     moves,
+	}
+}
+
+func (f *FastHand) seatView(seat int) SeatView {
+	return SeatView{
+		Id:         f.ids[seat],
+		Name:       f.ids[seat],
+		Chips:      f.stacks[seat],
+		Committed:  f.committed(seat),
+		Folded:     f.folded[seat],
+		NextToPlay: seat == f.next,
+	}
+}
+
+// finish awards the pot to the last player remaining, or divides it at a showdown, as for a Hand.
+func (f *FastHand) finish() {
+	f.finished = true
+	total := f.total()
+	if f.active == 1 {
+		for i, folded := range f.folded {
+			if !folded {
+				f.net[i] += total - f.rules.rake(total)
+			}
+		}
+		return
+	}
+
+	for i := range f.allIns {
+		f.allIns[i] = f.allIn(i)
+	}
+	f.stakes, f.amounts = sidePots(f.contribs, f.folded, f.allIns, f.stakes[:0], f.amounts[:0])
+	f.amounts[0] += f.dead
+	takeRake(f.rules.rake(total), f.amounts)
+	var seven [7]CardCode
+	copy(seven[2:], f.board[:])
+	for i := range f.ranks {
+		if !f.folded[i] {
+			seven[0], seven[1] = f.hole[i][0], f.hole[i][1]
+			f.ranks[i] = EvaluateCodes(seven[:2+f.cards])
+		}
+	}
+	for i, stake := range f.stakes {
+		var best HandRank
+		winners := 0
+		for j := range f.ranks {
+			if !f.eligible(j, stake) {
+				continue
+			}
+			switch rank := f.ranks[j]; {
+			case rank > best:
+				best, winners = rank, 1
+			case rank == best:
+				winners++
+			}
+		}
+		if winners == 0 {
+			continue
+		}
+		place := 0
+		for j := range f.ranks {
+			if !f.eligible(j, stake) || f.ranks[j] != best {
+				continue
+			}
+			f.net[j] += splitShare(f.amounts[i], winners, place, f.rules.OddChip)
+			place++
+		}
+	}
+}
+
+func (f *FastHand) eligible(seat int, stake int) bool {
+	return !f.folded[seat] && eligible(f.contribs[seat], f.allIns[seat], stake)
+}
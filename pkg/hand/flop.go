@@ -14,3 +14,7 @@ func newFlopState(remaining []*Player) flop {
 	bs := newBettingStage(remaining, 3, curr, next)
 	return flop{bs}
 }
+
+func (curr flop) street() Street {
+	return Flop
+}
@@ -1,28 +1,90 @@
 package hand
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"sync"
+	"time"
 
 	"github.com/rs/xid"
 )
 
 type Hand struct {
 	Id         string
+	seated     []*Player
+	seats      map[string]int
+	button     int
+	dealt      []*Player
+	waiting    []*Player
+	rules      Rules
+	blinds     []int
+	deck       *deck
 	players    []*Player
 	m          sync.RWMutex
 	finished   chan FinishedHand
+	done       chan struct{}
 	dealer     *Player
 	nextToPlay *Player
 	Cards      []Card
 	stage      stage
 	pot        pot
+	roundBase  map[string]int
+	aggressor  *Player
+	lastRaise  int
+	settled    int
+	runs       map[string]int
+	shown      map[string]bool
+	history    []Entry
+	log        []action
+	automatic  bool
+	broadcast  *broadcast
+	limits     *TimeLimits
+	banks      map[string]time.Duration
+	timer      turnTimer
 }
 
 type FinishedHand struct {
-	winner *Player
-	chips  int
+	winner    *Player
+	chips     int
+	cancelled bool
+	reason    string
+	showdown  *showdown
+}
+
+// Winner returns the player who won the hand, or nil if the hand was cancelled.
+func (fh FinishedHand) Winner() *Player { return fh.winner }
+
+// Chips returns the chips won.
+func (fh FinishedHand) Chips() int { return fh.chips }
+
+// Cancelled reports whether the hand was cancelled rather than won.
+func (fh FinishedHand) Cancelled() bool { return fh.cancelled }
+
+// Reason returns the reason the hand was cancelled.
+func (fh FinishedHand) Reason() string { return fh.reason }
+
+// Runs returns the result of each deal of the board if the hand went to a showdown.
+func (fh FinishedHand) Runs() []Run {
+	if fh.showdown == nil {
+		return nil
+	}
+	return append([]Run{}, fh.showdown.runs...)
+}
+
+// Awards returns the chips won by each player after any rake, which are added to their stacks when
+// the hand finishes.
+func (fh FinishedHand) Awards() map[string]int {
+	awards := make(map[string]int)
+	if fh.showdown != nil {
+		for id, v := range fh.showdown.awards {
+			awards[id] = v
+		}
+	} else if fh.winner != nil {
+		awards[fh.winner.Id] = fh.chips
+	}
+	return awards
 }
 
 // NewHand creates a new hand with the given players, dealer, and blinds. The dealer is a pointer to a
@@ -31,62 +93,139 @@ type FinishedHand struct {
 // After creating a hand, it would be typical to call Begin() to begin the hand, and to receive from the
 // channel that is returned.
 func NewHand(ps []*Player, dealer *Player, blinds ...int) (*Hand, error) {
+	return NewHandWithRules(ps, dealer, Rules{Blinds: blinds})
+}
+
+// NewHandWithRules creates a new hand with the given players and dealer, played according to the
+// rules. An error describing the problem is returned if the rules are invalid.
+func NewHandWithRules(ps []*Player, dealer *Player, rules Rules) (*Hand, error) {
 	id := xid.New().String()
-	// TODO: validate dealer is in ps
-	// TODO: validate blinds are positive
-	// TODO: validate blinds are less than or equal to the number of players
 	ch := make(chan FinishedHand, 1)
 	if len(ps) <= 1 {
-		return nil, errors.New("hand requires at least 2 players")
+		return nil, ErrNotEnoughPlayers
 	}
 
-	var dIdx int
+	dIdx := -1
 	for i, v := range ps {
 		if v == dealer {
 			dIdx = i
 		}
 	}
+	if dIdx < 0 {
+		return nil, invalidRules("dealer must be one of the players in the hand")
+	}
 
-	sortedPs := append(ps[dIdx:], ps[:dIdx]...)
+	// the players are only changed by being dealt in once the hand is known to be valid
+	blinds := rules.positionalBlinds()
+	ordered := append(ps[dIdx:], ps[:dIdx]...)
+	sortedPs := dealIn(ordered, blinds)
+	if len(sortedPs) <= 1 {
+		return nil, fmt.Errorf("%w who are not sitting out", ErrNotEnoughPlayers)
+	}
+	if err := rules.Validate(len(sortedPs)); err != nil {
+		return nil, err
+	}
+	dealer = sortedPs[0]
 
-	state, err := initialGameState(sortedPs, blinds)
+	state, err := initialGameState(sortedPs, blinds, rules)
 	if err != nil {
 		return nil, err
 	}
-	return &Hand{Id: id, players: sortedPs, pot: newPot(), dealer: dealer, stage: state, finished: ch}, nil
+	seatForHand(ordered, blinds)
+	seated := append([]*Player{}, ps...)
+	h := &Hand{
+		Id:        id,
+		seated:    seated,
+		dealt:     append([]*Player{}, sortedPs...),
+		rules:     rules,
+		blinds:    blinds,
+		players:   sortedPs,
+		pot:       newPot(),
+		shown:     make(map[string]bool),
+		dealer:    dealer,
+		stage:     state,
+		finished:  ch,
+		done:      make(chan struct{}),
+		lastRaise: rules.bigBlind(),
+	}
+	if rules.TimeLimits != nil {
+		h.setTimeLimits(*rules.TimeLimits)
+	}
+	if rules.Rand != nil {
+		h.deck = newDeck(rules.Rand)
+	}
+	return h, nil
 }
 
 // Begin begins the hand and returns a channel into which the hand result will be sent when the hand is finished.
-func (h *Hand) Begin() (chan FinishedHand, error) {
-	if h.IsActive() {
-		return nil, errors.New("hand already active so cannot begin")
+// The hand is cancelled if the context is done before the hand is finished. ErrHandFinished is returned
+// if the hand has already been won or cancelled.
+func (h *Hand) Begin(ctx context.Context) (chan FinishedHand, error) {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	if h.isFinished() {
+		return nil, ErrHandFinished
+	}
+	if h.isActive() {
+		return nil, ErrHandActive
 	}
+	h.deal()
+	h.postAntes()
+	h.postOwedBlinds()
+	h.openBetting()
 	h.playFromDealer()
+	h.settle()
+	h.record()
+	h.playAway()
+	h.startTurn()
+	h.cancelOnDone(ctx)
 	return h.finished, nil
 }
 
+// openBetting starts the first round of betting of a hand without blinds between the players able
+// to act as it begins, who include any who joined after it was created. With blinds the round is
+// started once they are posted.
+func (h *Hand) openBetting() {
+	if len(h.blinds) > 0 {
+		return
+	}
+	if h.rules.PreflopBetting {
+		h.stage = newPreflopBettingState(h.actors())
+		return
+	}
+	h.stage = newFlopState(h.actors())
+}
+
 func (h *Hand) IsActive() bool {
+	h.m.RLock()
+	defer h.m.RUnlock()
+
+	return h.isActive()
+}
+
+func (h *Hand) isActive() bool {
 	return h.nextToPlay != nil
 }
 
-// Join adds a player to the hand. If the hand has already begun, an error is returned.
+// Join adds a player to the hand with the given chips as their stack. If the hand has already begun,
+// the player waits for the next hand rather than being dealt in; see JoinNextHand.
 func (h *Hand) Join(player *Player, chips int) error {
-	for _, v := range h.players {
-		if v.Name == player.Name {
-			return errors.New("duplicate player name")
-		}
-	}
-	h.players = append(h.players, player)
-	return nil
+	return h.JoinNextHand(player, chips, EnterNextHand)
 }
 
-// Players returns the player denoted by the given ID and all opponents of that player in the hand
+// Players returns the player denoted by the given ID and all opponents of that player in the hand.
+// The returned players are copies of the players as the hand stands, so they may be read while the
+// hand is played from another goroutine, but changing them does not change the hand.
 func (h *Hand) Players(id string) (self *Player, opponents []*Player) {
+	h.m.RLock()
+	defer h.m.RUnlock()
+
 	for _, v := range h.players {
 		if v.Id == id {
-			self = v
+			self = v.clone()
 		} else {
-			opponents = append(opponents, v)
+			opponents = append(opponents, v.clone())
 		}
 	}
 
@@ -94,6 +233,9 @@ func (h *Hand) Players(id string) (self *Player, opponents []*Player) {
 }
 
 func (h *Hand) IsNextToPlay(playerId string) bool {
+	h.m.RLock()
+	defer h.m.RUnlock()
+
 	if h.nextToPlay == nil {
 		return false
 	}
@@ -102,6 +244,9 @@ func (h *Hand) IsNextToPlay(playerId string) bool {
 
 // ValidMoves returns a map of valid moves for all active players.
 func (h *Hand) ValidMoves() map[string][]Move {
+	h.m.RLock()
+	defer h.m.RUnlock()
+
 	if h.nextToPlay == nil {
 		return make(map[string][]Move)
 	}
@@ -109,57 +254,170 @@ func (h *Hand) ValidMoves() map[string][]Move {
 }
 
 func (h *Hand) PlayBlind(player string, amount int) error {
-	var p *Player
-	for _, v := range h.players {
-		if v.Id == player {
-			p = v
-			break
-		}
-	}
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	p := h.player(player)
 	if p == nil {
-		return errors.New("player not found in hand")
+		return ErrPlayerNotFound
 	}
-	return h.HandleInput(p, Input{Action: Blind, Chips: amount})
+	return h.handleInput(p, Input{Action: Blind, Chips: amount})
+}
 
+// Play plays the given input for the player denoted by the given ID.
+func (h *Hand) Play(playerId string, inp Input) error {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	p := h.player(playerId)
+	if p == nil {
+		return ErrPlayerNotFound
+	}
+	return h.handleInput(p, inp)
 }
 
+// HandleInput plays the given input for the player. It is safe to call concurrently with the other
+// methods of the hand.
 func (h *Hand) HandleInput(p *Player, inp Input) error {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	return h.handleInput(p, inp)
+}
+
+func (h *Hand) handleInput(p *Player, inp Input) error {
+	if err := h.play(p, inp); err != nil {
+		return err
+	}
+	h.playAway()
+	h.startTurn()
+	return nil
+}
+
+func (h *Hand) play(p *Player, inp Input) error {
 	if p != h.nextToPlay {
-		return &outOfTurnError{p, h.nextToPlay}
+		return h.outOfTurn(p)
 	}
+	before := h.snapshot()
+	bet := h.currentBet()
 	s, err := h.stage.handleInput(h, p, inp)
 	if err != nil {
+		h.restore(before)
 		return err
 	}
+	h.endTurn(p)
+	h.logMove(p, inp, before)
+	defer h.record()
+	if curr := h.currentBet(); inp.Action != Blind && curr > bet {
+		h.aggressor = p
+		if curr-bet > h.lastRaise {
+			h.lastRaise = curr - bet
+		}
+	}
 	if s != nil {
-		curr := fmt.Sprintf("%T", s)
-		new := fmt.Sprintf("%T", h.stage)
-		if curr != new {
-			h.stage.exit(h)
-			s.enter(h)
+		if reflect.TypeOf(s) != reflect.TypeOf(h.stage) {
+			h.settled = len(h.Cards)
+			h.advance(s)
+			h.runOut()
 		} else {
 			h.nextMove()
+			h.stage = s
 		}
-		h.stage = s
 		return nil
 	}
 	h.nextMove()
 	return nil
 }
 
+// outOfTurn returns the error for the player attempting to play when it is not their turn.
+func (h *Hand) outOfTurn(p *Player) error {
+	err := &OutOfTurnError{PlayerId: p.Id}
+	if h.nextToPlay != nil {
+		err.NextToPlayId = h.nextToPlay.Id
+	}
+	return err
+}
+
+// advance moves the hand on to the next stage.
+func (h *Hand) advance(s stage) {
+	h.stage.exit(h)
+	s.enter(h)
+	h.startStreet(h.stage)
+	h.stage = s
+}
+
+// runOut deals the remaining streets without betting while fewer than two players are able to act
+// and none of them has a stake to call.
+func (h *Hand) runOut() {
+	for {
+		bs, ok := h.stage.(interface{ next(h *Hand) stage })
+		if !ok {
+			return
+		}
+		actors := h.actors()
+		if len(actors) > 1 || (len(actors) == 1 && h.pot.required(*actors[0]) > 0) {
+			return
+		}
+		h.advance(bs.next(h))
+	}
+}
+
+// settle moves the hand on from the blinds if none are left to post, such as when those who owe
+// them are all-in from the antes, and deals the board if too few players are able to bet.
+func (h *Hand) settle() {
+	if pf, ok := h.stage.(preflop); ok && pf.posted() {
+		h.advance(pf.afterBlinds(h))
+	}
+	h.runOut()
+}
+
 func (h *Hand) finish(fh FinishedHand) {
 	h.finished <- fh
 	close(h.finished)
+	close(h.done)
 }
 
 func (h *Hand) playFromDealer() {
-	h.nextToPlay = h.dealer
+	h.playFrom(h.dealer)
 }
 
-func (h *Hand) activePlayers() []*Player {
-	h.m.Lock()
-	defer h.m.Unlock()
+// playFromFirst passes play to the first player to act on a street after the first betting round.
+// That is the dealer, except heads up with two blinds when the dealer posts the small blind and so
+// acts last.
+func (h *Hand) playFromFirst() {
+	if len(h.dealt) == 2 && len(h.blinds) == 2 {
+		h.playFrom(h.dealt[1])
+		return
+	}
+	h.playFromDealer()
+}
 
+// playFrom passes play to the player, or the next after them who is able to act.
+func (h *Hand) playFrom(p *Player) {
+	h.nextToPlay = p
+	if !h.canAct(p) {
+		h.nextMove()
+	}
+}
+
+// canAct reports whether the player is still in the hand and has chips with which to act.
+func (h *Hand) canAct(p *Player) bool {
+	return !p.Folded && !h.pot.isAllIn(p)
+}
+
+// actors returns the players able to act in order from the dealer.
+func (h *Hand) actors() []*Player {
+	var ps []*Player
+	for _, v := range h.players {
+		if h.canAct(v) {
+			ps = append(ps, v)
+		}
+	}
+	return ps
+}
+
+// activePlayers and the other unexported methods of the hand assume that the caller holds the lock.
+func (h *Hand) activePlayers() []*Player {
 	var active []*Player
 	for _, v := range h.players {
 		if !v.Folded {
@@ -171,19 +429,17 @@ func (h *Hand) activePlayers() []*Player {
 }
 
 func (h *Hand) activePlayerAt(idx int) (*Player, error) {
-	if idx > len(h.activePlayers()) {
-		return nil, errors.New("index out of range of active players")
-	}
 	ps, err := h.activePlayersAt(idx, idx+1)
-	return ps[0], err
+	if err != nil {
+		return nil, err
+	}
+	return ps[0], nil
 }
 
 func (h *Hand) activePlayersAt(startIdx int, endIdx int) ([]*Player, error) {
 	if startIdx > endIdx {
 		return nil, errors.New("start index must preceed or equal end index")
 	}
-	h.m.Lock()
-	defer h.m.Unlock()
 
 	var dIdx int
 	var active []*Player
@@ -211,59 +467,64 @@ func (h *Hand) activePlayersAt(startIdx int, endIdx int) ([]*Player, error) {
 	return append(active[i:], active[:j]...), nil
 }
 
-func initialGameState(ps []*Player, blinds []int) (stage, error) {
+func initialGameState(ps []*Player, blinds []int, rules Rules) (stage, error) {
+	if len(blinds) == 0 && rules.PreflopBetting {
+		return newPreflopBettingState(ps), nil
+	}
 	if len(blinds) == 0 {
 		return newFlopState(ps), nil
 	}
 	return newPreflop(ps, blinds)
 }
 
-type betTooLowError struct {
-	player         Player
-	betAmount      int
-	requiredAmount int
-}
-
-func (e betTooLowError) Error() string {
-	return fmt.Sprintf("bet of %d played by %v is too low; %d required", e.betAmount, e.player, e.requiredAmount)
-}
-
-type unexpectedBetAmountError struct {
-	player    Player
-	betAmount int
-}
-
-func (e unexpectedBetAmountError) Error() string {
-	return fmt.Sprintf("bet of %d played by %v is unexpected", e.betAmount, e.player)
-}
-
-type outOfTurnError struct {
-	attempted  *Player
-	nextToPlay *Player
+func (h *Hand) tableCard(num int) {
+	cs := make([]Card, num)
+	if h.deck != nil {
+		cs = h.deck.deal(num)
+	}
+	h.Cards = append(h.Cards, cs...)
 }
 
-func (e outOfTurnError) Error() string {
-	return fmt.Sprintf("%v is next to play but %v attempted", e.nextToPlay, e.attempted)
+// deal deals hole cards to each player from the deck, if the hand has one.
+func (h *Hand) deal() {
+	if h.deck == nil {
+		return
+	}
+	for _, v := range h.players {
+		v.Cards = h.deck.deal(h.rules.holeCards())
+	}
 }
 
-func (h *Hand) tableCard(num int) {
-	cs := make([]Card, num)
-	h.Cards = append(h.Cards, cs...)
+func (h *Hand) postAntes() {
+	if h.rules.Ante == 0 {
+		return
+	}
+	for _, v := range h.players {
+		// a player with no more than the ante is all-in having posted it
+		h.pot.addDead(v, min(h.rules.Ante, v.Chips))
+	}
 }
 
+// nextMove passes play to the next player in order from the dealer who is able to act.
 func (h *Hand) nextMove() {
 	var playIdx int
-	for i, v := range h.players {
+	for i, v := range h.dealt {
 		if h.nextToPlay == v {
 			playIdx = i
 		}
 	}
-	h.nextToPlay = h.players[(playIdx+1)%len(h.players)]
+	for i := 1; i <= len(h.dealt); i++ {
+		p := h.dealt[(playIdx+i)%len(h.dealt)]
+		if h.canAct(p) {
+			h.nextToPlay = p
+			return
+		}
+	}
 }
 
 func (h *Hand) fold(p *Player) ([]*Player, error) {
 	if len(h.players) == 1 {
-		return nil, errors.New("final player cannot fold")
+		return nil, &ActionError{PlayerId: p.Id, Action: Fold, Street: h.stage.street(), Reason: "final player cannot fold"}
 	}
 	var idx int
 	for i, v := range h.players {
@@ -276,6 +537,7 @@ func (h *Hand) fold(p *Player) ([]*Player, error) {
 	copy(ret[:idx], h.players[:idx])
 	copy(ret[idx:], h.players[idx+1:])
 	h.players = ret
+	p.Folded = true
 
 	return ret, nil
 }
@@ -283,25 +545,89 @@ func (h *Hand) fold(p *Player) ([]*Player, error) {
 func (h *Hand) check(p *Player) error {
 	req := h.pot.required(*p)
 	if req != 0 {
-		return errors.New("cannot check when required is not zero")
+		return &ActionError{PlayerId: p.Id, Action: Check, Street: h.stage.street(), Reason: fmt.Sprintf("%d required to call", req)}
 	}
 	return nil
 }
 
+// call adds the chips required to match the highest stake, or all of the player's chips if they
+// have fewer.
 func (h *Hand) call(p *Player) error {
 	req := h.pot.required(*p)
+	if req > p.Chips {
+		req = p.Chips
+	}
 	h.pot.add(p, req)
 	return nil
 }
 
-func (h *Hand) raise(p *Player, bet int) error {
-	req := h.pot.required(*p)
-	if bet < req {
-		return betTooLowError{*p, bet, req}
+// raise raises the highest stake on the street to the given total, or by the given increment if
+// the rules raise in increments.
+func (h *Hand) raise(p *Player, chips int) error {
+	w := h.wager(p)
+	to := w.raiseTo(chips)
+	allowed := w.raiseRange()
+	if to < allowed.Minimum || to > allowed.Maximum {
+		return &BetError{PlayerId: p.Id, Action: Raise, Chips: chips, Allowed: w.inRaiseUnit(allowed)}
 	}
-	if bet == req {
-		return unexpectedBetAmountError{*p, bet}
+	h.pot.add(p, to-w.committed)
+	return nil
+}
+
+// wager returns the betting on this street as it stands for the player.
+func (h *Hand) wager(p *Player) wager {
+	return wager{
+		limit:     h.rules.Limit,
+		raises:    h.rules.Raises,
+		bigBlind:  h.rules.bigBlind(),
+		street:    h.stage.street(),
+		current:   h.currentBet(),
+		lastRaise: h.lastRaise,
+		committed: h.committed(p),
+		required:  h.pot.required(*p),
+		chips:     p.Chips,
+		pot:       h.pot.total(),
+	}
+}
+
+// committed returns the chips the player has committed on this street.
+func (h *Hand) committed(p *Player) int {
+	return h.pot.contribs[p.Id] - h.roundBase[p.Id]
+}
+
+// bet opens the betting on a street on which no wager has been made.
+func (h *Hand) bet(p *Player, bet int) error {
+	if h.currentBet() > 0 {
+		return &ActionError{PlayerId: p.Id, Action: Bet, Street: h.stage.street(), Reason: "betting is already open so must raise"}
+	}
+	allowed := h.wager(p).betRange()
+	if bet < allowed.Minimum || bet > allowed.Maximum {
+		return &BetError{PlayerId: p.Id, Action: Bet, Chips: bet, Allowed: allowed}
 	}
 	h.pot.add(p, bet)
 	return nil
 }
+
+// allIn adds all of the player's chips to the pot regardless of the minimum bet or raise. The chips
+// played must be either zero or the player's entire stack.
+func (h *Hand) allIn(p *Player, chips int) error {
+	if p.Chips <= 0 {
+		return &ActionError{PlayerId: p.Id, Action: AllIn, Street: h.stage.street(), Reason: "no chips remaining"}
+	}
+	if chips != 0 && chips != p.Chips {
+		return &BetError{PlayerId: p.Id, Action: AllIn, Chips: chips, Allowed: NewExactBet(p.Chips)}
+	}
+	h.pot.add(p, p.Chips)
+	return nil
+}
+
+// currentBet returns the highest amount committed by a player on this street.
+func (h *Hand) currentBet() int {
+	max := 0
+	for id, v := range h.pot.contribs {
+		if c := v - h.roundBase[id]; c > max {
+			max = c
+		}
+	}
+	return max
+}
@@ -1,9 +1,14 @@
 package hand
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
 	"reflect"
+	"runtime"
+	"sync"
 	"testing"
 	"time"
 )
@@ -154,7 +159,7 @@ func TestAllPlayersCallTheBlind(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	h.Begin()
+	h.Begin(context.Background())
 
 	// preflop
 	if p1.Chips != initial {
@@ -214,7 +219,7 @@ func TestOneFoldsAndOneCallsBlind(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	h.Begin()
+	h.Begin(context.Background())
 
 	if p1.Chips != initial {
 		t.Errorf("Player 1 should have %d chips before playing blind but has %d", initial, p1.Chips)
@@ -259,7 +264,7 @@ func TestOneFoldsAndOneChecksBlind(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	h.Begin()
+	h.Begin(context.Background())
 
 	if p1.Chips != initial {
 		t.Errorf("Player 1 should have %d chips before playing blind but has %d", initial, p1.Chips)
@@ -320,7 +325,7 @@ func TestBlindsPlayedFromDealer(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	h.Begin()
+	h.Begin(context.Background())
 
 	if err = playBlind(h, p2); err != nil {
 		t.Error(err)
@@ -356,7 +361,7 @@ func TestSecondCallInWrongOrderReturnsError(t *testing.T) {
 	p3 := createPlayer()
 	players := []*Player{p1, p2, p3}
 	h, _ := NewHand(players, p1, smallBlind)
-	h.Begin()
+	h.Begin(context.Background())
 
 	if err := playBlind(h, p1); err != nil {
 		t.Error(err)
@@ -468,7 +473,7 @@ func TestPlayerFoldsAfterReraise(t *testing.T) {
 	if err := playRaise(th.h, th.p2, smallBlind+1); err != nil {
 		t.Error(err)
 	}
-	if err := playRaise(th.h, th.p1, 2); err != nil {
+	if err := playRaise(th.h, th.p1, 3); err != nil {
 		t.Error(err)
 	}
 	if err := playFold(th.h, th.p2); err != nil {
@@ -525,7 +530,8 @@ func TestValidMovesInFlopReturnsBettableMoves(t *testing.T) {
 	moves := []Move{
 		NewMove(Fold, RequiredBet{}),
 		NewMove(Check, RequiredBet{}),
-		NewMove(Raise, NewMinumumBet(0)),
+		NewMove(Bet, NewBetRange(1, initial)),
+		NewMove(AllIn, NewExactBet(initial)),
 	}
 	want[th.p1.Id] = moves
 	if !reflect.DeepEqual(got, want) {
@@ -545,7 +551,8 @@ func TestValidMovesInFlopWithOutstandingBetReturnsBettableMoves(t *testing.T) {
 	moves := []Move{
 		NewMove(Fold, RequiredBet{}),
 		NewMove(Call, NewExactBet(1)),
-		NewMove(Raise, NewMinumumBet(1)),
+		NewMove(Raise, NewBetRange(2, initial)),
+		NewMove(AllIn, NewExactBet(initial)),
 	}
 	want[th.p2.Id] = moves
 	if !reflect.DeepEqual(got, want) {
@@ -584,7 +591,7 @@ func TestNoValidMovesBeforeGameBegins(t *testing.T) {
 func TestCallingBeginTwiceReturnsError(t *testing.T) {
 	th := createMinimalHand(t)
 
-	if _, err := th.h.Begin(); err == nil {
+	if _, err := th.h.Begin(context.Background()); err == nil {
 		t.Error("Expected error for calling begin twice but none received")
 	}
 }
@@ -593,14 +600,25 @@ func TestGetPlayersReturnsPlayerAndOpponents(t *testing.T) {
 	th := createMinimalHand(t)
 
 	self, opponents := th.h.Players(th.p1.Id)
-	if self != th.p1 {
+	if !reflect.DeepEqual(self, th.p1) {
 		t.Errorf("expected %v but got %v", th.p1, self)
 	}
-	if len(opponents) != 1 && opponents[0] != th.p2 {
+	if len(opponents) != 1 || !reflect.DeepEqual(opponents[0], th.p2) {
 		t.Errorf("expected %v but got %v", th.p2, opponents)
 	}
 }
 
+func TestPlayersAreCopiedFromHand(t *testing.T) {
+	th := createMinimalHand(t)
+
+	self, _ := th.h.Players(th.p1.Id)
+	self.Chips = 0
+
+	if th.p1.Chips != initial {
+		t.Errorf("expected player in hand to keep %d chips but has %d", initial, th.p1.Chips)
+	}
+}
+
 func TestIsNextToPlayReturnsFalseBeforeGameBegins(t *testing.T) {
 	p1 := createPlayer()
 	p2 := createPlayer()
@@ -694,7 +712,7 @@ func createMinimalHand(t *testing.T) testHand {
 	if err != nil {
 		t.Error(err)
 	}
-	fin, err := h.Begin()
+	fin, err := h.Begin(context.Background())
 	if err != nil {
 		t.Error(err)
 	}
@@ -710,7 +728,7 @@ func createMinimalHandWithBlind(t *testing.T) testHand {
 	if err != nil {
 		t.Error(err)
 	}
-	fin, err := h.Begin()
+	fin, err := h.Begin(context.Background())
 	if err != nil {
 		t.Error(err)
 	}
@@ -763,3 +781,2416 @@ func checkPlayers(t *testing.T, ps []*Player, rem ...*Player) {
 		}
 	}
 }
+
+func TestConcurrentPlayersPlayHandToCompletion(t *testing.T) {
+	const numPlayers = 8
+	players := make([]*Player, numPlayers)
+	for i := range players {
+		players[i] = createPlayer()
+	}
+	h, err := NewHand(players, players[0], smallBlind, bigBlind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fin, err := h.Begin(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for _, v := range players {
+		wg.Add(2)
+		go func(p *Player) {
+			defer wg.Done()
+			playPassively(t, h, p, done)
+		}(v)
+		go func(id string) {
+			defer wg.Done()
+			observe(t, h, id, done)
+		}(v.Id)
+	}
+
+	select {
+	case fh := <-fin:
+		if fh.chips != bigBlind*numPlayers {
+			t.Errorf("expected pot of %d but got %d", bigBlind*numPlayers, fh.chips)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("timed out waiting for concurrently played hand to finish")
+	}
+	close(done)
+	wg.Wait()
+}
+
+// playPassively plays the first blind, check or call available to the player whenever it is their turn.
+func playPassively(t *testing.T, h *Hand, p *Player, done chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		if !h.IsNextToPlay(p.Id) {
+			runtime.Gosched()
+			continue
+		}
+		for _, mv := range h.ValidMoves()[p.Id] {
+			if mv.Action == Fold || mv.Action == Raise {
+				continue
+			}
+			if err := h.HandleInput(p, Input{Action: mv.Action, Chips: mv.Bet.Minimum}); err != nil {
+				t.Error(err)
+			}
+			break
+		}
+	}
+}
+
+// observe repeatedly reads the state of the hand as a client rendering it would.
+func observe(t *testing.T, h *Hand, id string, done chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		h.IsActive()
+		h.ValidMoves()
+		self, opponents := h.Players(id)
+		for _, p := range append(opponents, self) {
+			if p != nil && (p.Chips < 0 || len(p.Cards) > 2) {
+				t.Errorf("expected player to be shown with a stack and hole cards but got %+v", p)
+			}
+		}
+		h.IsNextToPlay(id)
+		runtime.Gosched()
+	}
+}
+
+func TestPlayerWhoTimesOutChecksWhenPossible(t *testing.T) {
+	clock := newFakeClock()
+	var notices []Timeout
+	th := createTimedHand(t, clock, func(to Timeout) { notices = append(notices, to) })
+
+	clock.Advance(timeLimit + timeBank)
+
+	if !th.h.IsNextToPlay(th.p2.Id) {
+		t.Error("expected play to pass to next player after timeout")
+	}
+	want := []Timeout{{HandId: th.h.Id, PlayerId: th.p1.Id, Input: Input{Action: Check}}}
+	if !reflect.DeepEqual(notices, want) {
+		t.Errorf("expected %v but got %v", want, notices)
+	}
+}
+
+func TestPlayerWhoTimesOutFoldsWhenBetIsDue(t *testing.T) {
+	clock := newFakeClock()
+	var notices []Timeout
+	th := createTimedHand(t, clock, func(to Timeout) { notices = append(notices, to) })
+	if err := playRaise(th.h, th.p1, 2); err != nil {
+		t.Error(err)
+	}
+
+	clock.Advance(timeLimit + timeBank)
+
+	want := FinishedHand{winner: th.p1, chips: 2}
+	if fin := <-th.fin; fin != want {
+		t.Errorf("expected %v but got %v", want, fin)
+	}
+	if len(notices) != 1 || notices[0].Input.Action != Fold {
+		t.Errorf("expected a single fold notice but got %v", notices)
+	}
+}
+
+func TestPlayerActingInTimeDoesNotTimeOut(t *testing.T) {
+	clock := newFakeClock()
+	var notices []Timeout
+	th := createTimedHand(t, clock, func(to Timeout) { notices = append(notices, to) })
+
+	clock.Advance(timeLimit - time.Second)
+	if err := playCheck(th.h, th.p1); err != nil {
+		t.Error(err)
+	}
+	clock.Advance(time.Second)
+
+	if len(notices) != 0 {
+		t.Errorf("expected no timeouts but got %v", notices)
+	}
+	if bank := th.h.TimeBank(th.p1.Id); bank != timeBank {
+		t.Errorf("expected time bank of %v but got %v", timeBank, bank)
+	}
+}
+
+func TestTimeBankIsDrawnDownAfterTimeLimit(t *testing.T) {
+	clock := newFakeClock()
+	var notices []Timeout
+	th := createTimedHand(t, clock, func(to Timeout) { notices = append(notices, to) })
+
+	clock.Advance(timeLimit + time.Second)
+	if err := playCheck(th.h, th.p1); err != nil {
+		t.Error(err)
+	}
+	if bank := th.h.TimeBank(th.p1.Id); bank != timeBank-time.Second {
+		t.Errorf("expected time bank of %v but got %v", timeBank-time.Second, bank)
+	}
+
+	clock.Advance(timeLimit + timeBank)
+	if len(notices) != 1 || notices[0].PlayerId != th.p2.Id {
+		t.Errorf("expected second player to time out but got %v", notices)
+	}
+	if bank := th.h.TimeBank(th.p2.Id); bank != 0 {
+		t.Errorf("expected time bank to be exhausted but got %v", bank)
+	}
+}
+
+func TestTimerRestartsWhenTimeoutCannotBePlayed(t *testing.T) {
+	clock := newFakeClock()
+	var notices []Timeout
+	th := createTimedHand(t, clock, func(to Timeout) { notices = append(notices, to) })
+	th.h.m.Lock()
+	playable := th.h.stage
+	th.h.stage = rejectingStage{playable}
+	th.h.m.Unlock()
+
+	clock.Advance(timeLimit + timeBank)
+	if len(notices) != 0 || !th.h.IsNextToPlay(th.p1.Id) {
+		t.Fatalf("expected the automatic move to fail but got %v", notices)
+	}
+
+	th.h.m.Lock()
+	th.h.stage = playable
+	th.h.m.Unlock()
+	clock.Advance(timeLimit)
+	if len(notices) != 1 || notices[0].PlayerId != th.p1.Id {
+		t.Errorf("expected the player to time out again but got %v", notices)
+	}
+}
+
+// rejectingStage is a stage in which every input fails.
+type rejectingStage struct {
+	stage
+}
+
+func (s rejectingStage) handleInput(h *Hand, p *Player, inp Input) (stage, error) {
+	return nil, &ActionError{PlayerId: p.Id, Action: inp.Action, Street: s.street(), Reason: "rejected"}
+}
+
+func (s rejectingStage) clone() stage {
+	return rejectingStage{s.stage.clone()}
+}
+
+func TestTimeLimitsCannotBeSetAfterHandBegins(t *testing.T) {
+	th := createMinimalHand(t)
+
+	if err := th.h.SetTimeLimits(TimeLimits{PerAction: timeLimit}); err == nil {
+		t.Error("expected error setting time limits on an active hand but none received")
+	}
+}
+
+const (
+	timeLimit = 30 * time.Second
+	timeBank  = 60 * time.Second
+)
+
+func createTimedHand(t *testing.T, clock Clock, onTimeout func(Timeout)) testHand {
+	p1 := createPlayer()
+	p2 := createPlayer()
+	h, err := NewHand([]*Player{p1, p2}, p1)
+	if err != nil {
+		t.Error(err)
+	}
+	tl := TimeLimits{PerAction: timeLimit, TimeBank: timeBank, Clock: clock, OnTimeout: onTimeout}
+	if err := h.SetTimeLimits(tl); err != nil {
+		t.Error(err)
+	}
+	fin, err := h.Begin(context.Background())
+	if err != nil {
+		t.Error(err)
+	}
+	return testHand{h, p1, p2, fin}
+}
+
+// fakeClock is a Clock whose time only moves when advanced, running due timers synchronously.
+type fakeClock struct {
+	m      sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	c       *fakeClock
+	at      time.Time
+	f       func()
+	stopped bool
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(0, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.m.Lock()
+	defer c.m.Unlock()
+	t := &fakeTimer{c: c, at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.m.Lock()
+	c.now = c.now.Add(d)
+	var due []*fakeTimer
+	var pending []*fakeTimer
+	for _, v := range c.timers {
+		if v.stopped {
+			continue
+		}
+		if v.at.After(c.now) {
+			pending = append(pending, v)
+		} else {
+			v.stopped = true
+			due = append(due, v)
+		}
+	}
+	c.timers = pending
+	c.m.Unlock()
+
+	for _, v := range due {
+		v.f()
+	}
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.c.m.Lock()
+	defer t.c.m.Unlock()
+	wasActive := !t.stopped
+	t.stopped = true
+	return wasActive
+}
+
+func TestPlayerSittingOutIsDealtOutAndMissesBlind(t *testing.T) {
+	p1 := createPlayer()
+	p2 := createPlayer()
+	p3 := createPlayer()
+	p1.SittingOut = true
+
+	h, err := NewHand([]*Player{p1, p2, p3}, p1, smallBlind, bigBlind)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkPlayers(t, h.players, p2, p3)
+	if p1.MissedBlinds != smallBlind {
+		t.Errorf("expected missed blinds of %d but got %d", smallBlind, p1.MissedBlinds)
+	}
+	if p1.HandsSatOut != 1 {
+		t.Errorf("expected 1 hand sat out but got %d", p1.HandsSatOut)
+	}
+	if p1.Chips != initial {
+		t.Errorf("expected player sitting out to keep %d chips but has %d", initial, p1.Chips)
+	}
+}
+
+func TestRejectedHandDoesNotChargePlayerSittingOut(t *testing.T) {
+	p1 := createPlayer()
+	p2 := createPlayer()
+	p3 := createPlayer()
+	p1.SittingOut = true
+
+	if _, err := NewHandWithRules([]*Player{p1, p2, p3}, p1, Rules{Blinds: []int{smallBlind, bigBlind}, Ante: -1}); !errors.Is(err, ErrInvalidRules) {
+		t.Fatalf("expected %v but got %v", ErrInvalidRules, err)
+	}
+	if p1.MissedBlinds != 0 || p1.HandsSatOut != 0 {
+		t.Errorf("expected no blinds missed or hands sat out but got %d and %d", p1.MissedBlinds, p1.HandsSatOut)
+	}
+}
+
+func TestHandRequiresTwoPlayersNotSittingOut(t *testing.T) {
+	p1 := createPlayer()
+	p2 := createPlayer()
+	p2.SittingOut = true
+
+	if _, err := NewHand([]*Player{p1, p2}, p1); err == nil {
+		t.Error("expected error for hand with one player not sitting out but none received")
+	}
+}
+
+func TestPlayerSittingOutMidHandFoldsOnTheirTurn(t *testing.T) {
+	th := createMinimalHand(t)
+
+	if err := th.h.SitOut(th.p2.Id); err != nil {
+		t.Error(err)
+	}
+	if err := playRaise(th.h, th.p1, 2); err != nil {
+		t.Error(err)
+	}
+
+	want := FinishedHand{winner: th.p1, chips: 2}
+	if fin := <-th.fin; fin != want {
+		t.Errorf("expected %v but got %v", want, fin)
+	}
+}
+
+// staleMoves is a stage which offers moves that may no longer be valid in the stage it wraps.
+type staleMoves struct {
+	stage
+	moves []Move
+}
+
+func (s staleMoves) validMoves(h *Hand) map[string][]Move {
+	return map[string][]Move{h.nextToPlay.Id: s.moves}
+}
+
+func TestPlayerSittingOutIsFoldedWhenTheirMoveIsRejected(t *testing.T) {
+	th := createMinimalHand(t)
+	if err := playBet(th.h, th.p1, 2); err != nil {
+		t.Error(err)
+	}
+	th.p2.SittingOut = true
+	th.h.stage = staleMoves{th.h.stage, []Move{NewMove(Blind, NewExactBet(2))}}
+
+	th.h.playAway()
+
+	want := FinishedHand{winner: th.p1, chips: 2}
+	select {
+	case fin := <-th.fin:
+		if fin != want {
+			t.Errorf("expected %v but got %v", want, fin)
+		}
+	default:
+		t.Error("expected player sitting out to be folded rather than left to play")
+	}
+}
+
+func TestPlayerSittingOutWhenNextToPlayFoldsImmediately(t *testing.T) {
+	th := createMinimalHand(t)
+
+	if err := th.h.SitOut(th.p1.Id); err != nil {
+		t.Error(err)
+	}
+
+	want := FinishedHand{winner: th.p2}
+	if fin := <-th.fin; fin != want {
+		t.Errorf("expected %v but got %v", want, fin)
+	}
+}
+
+func TestPruneAwayRemovesPlayersSatOutForOrbits(t *testing.T) {
+	p1 := createPlayer()
+	p2 := createPlayer()
+	p3 := createPlayer()
+	p2.SittingOut = true
+	p2.HandsSatOut = 6
+	p3.SittingOut = true
+	p3.HandsSatOut = 5
+
+	seated, removed := PruneAway([]*Player{p1, p2, p3}, 2)
+
+	if !reflect.DeepEqual(seated, []*Player{p1, p3}) {
+		t.Errorf("expected %v to remain seated but got %v", []*Player{p1, p3}, seated)
+	}
+	if !reflect.DeepEqual(removed, []*Player{p2}) {
+		t.Errorf("expected %v to be removed but got %v", []*Player{p2}, removed)
+	}
+}
+
+func TestPlayerJoiningSetsTheirChips(t *testing.T) {
+	p1 := createPlayer()
+	p2 := createPlayer()
+	h, err := NewHand([]*Player{p1, p2}, p1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p3 := createPlayer()
+
+	if err := h.Join(p3, 2*initial); err != nil {
+		t.Error(err)
+	}
+	if p3.Chips != 2*initial {
+		t.Errorf("expected joining player to have %d chips but has %d", 2*initial, p3.Chips)
+	}
+	checkPlayers(t, h.players, p1, p2, p3)
+}
+
+func TestPlayerJoiningBeforeHandBeginsActsInFirstRound(t *testing.T) {
+	p1 := createPlayer()
+	p2 := createPlayer()
+	h, err := NewHand([]*Player{p1, p2}, p1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p3 := createPlayer()
+	if err := h.Join(p3, initial); err != nil {
+		t.Fatal(err)
+	}
+	h.Begin(context.Background())
+
+	if err := playCheck(h, p1); err != nil {
+		t.Error(err)
+	}
+	if err := playCheck(h, p2); err != nil {
+		t.Error(err)
+	}
+
+	if s := h.State(); s.Street != Flop || s.NextToPlay != p3.Id {
+		t.Errorf("expected joined player to act before the round closes but got %+v", s)
+	}
+}
+
+func TestPlayerCannotJoinWithoutChips(t *testing.T) {
+	th := createMinimalHand(t)
+
+	if err := th.h.Join(createPlayer(), 0); err == nil {
+		t.Error("expected error joining without chips but none received")
+	}
+}
+
+func TestPlayerJoiningActiveHandWaitsForNextHand(t *testing.T) {
+	th := createMinimalHand(t)
+	p3 := createPlayer()
+
+	if err := th.h.Join(p3, initial); err != nil {
+		t.Error(err)
+	}
+
+	checkPlayers(t, th.h.players, th.p1, th.p2)
+	if !reflect.DeepEqual(th.h.Waiting(), []*Player{p3}) {
+		t.Errorf("expected %v to be waiting but got %v", p3, th.h.Waiting())
+	}
+	if !reflect.DeepEqual(th.h.NextPlayers(), []*Player{th.p1, th.p2, p3}) {
+		t.Errorf("expected waiting player to be seated for next hand but got %v", th.h.NextPlayers())
+	}
+	if _, ok := th.h.ValidMoves()[p3.Id]; ok {
+		t.Error("expected waiting player to have no valid moves")
+	}
+}
+
+func TestPlayerJoiningToPostBigBlindPostsInNextHand(t *testing.T) {
+	p1 := createPlayer()
+	p2 := createPlayer()
+	h, err := NewHand([]*Player{p1, p2}, p1, smallBlind, bigBlind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Begin(context.Background())
+	p3 := createPlayer()
+	if err := h.JoinNextHand(p3, initial, PostBigBlind); err != nil {
+		t.Error(err)
+	}
+
+	next, err := NewHand(h.NextPlayers(), p1, smallBlind, bigBlind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next.Begin(context.Background())
+
+	if p3.Chips != initial-bigBlind {
+		t.Errorf("expected joining player to post big blind leaving %d chips but has %d", initial-bigBlind, p3.Chips)
+	}
+	if p3.MissedBlinds != 0 {
+		t.Errorf("expected no blinds owed once posted but got %d", p3.MissedBlinds)
+	}
+}
+
+func TestPlayerOwingMoreThanStackPostsAllTheirChips(t *testing.T) {
+	p1 := createPlayer()
+	p2 := createPlayer()
+	p3 := NewPlayer("short", 3)
+	p3.MissedBlinds = smallBlind + bigBlind + 2
+	h, err := NewHand([]*Player{p1, p2, p3}, p1, smallBlind, bigBlind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Begin(context.Background())
+
+	if p3.Chips != 0 {
+		t.Errorf("expected player to post no more than their stack but has %d chips", p3.Chips)
+	}
+	if got := h.State().Committed[p3.Id]; got != bigBlind {
+		t.Errorf("expected a big blind posted live but committed %d", got)
+	}
+}
+
+func TestPlayerJoiningToWaitForButtonIsDealtInAtBigBlind(t *testing.T) {
+	p1 := createPlayer()
+	p2 := createPlayer()
+	p3 := createPlayer()
+	h, err := NewHand([]*Player{p1, p2, p3}, p1, smallBlind, bigBlind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Begin(context.Background())
+	p4 := createPlayer()
+	if err := h.JoinNextHand(p4, initial, WaitForButton); err != nil {
+		t.Error(err)
+	}
+	ps := h.NextPlayers()
+
+	next, err := NewHand(ps, p2, smallBlind, bigBlind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPlayers(t, next.players, p2, p3, p1)
+
+	next, err = NewHand(ps, p3, smallBlind, bigBlind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPlayers(t, next.players, p3, p4, p1, p2)
+}
+
+func TestStateReportsStreetPotAndCommitments(t *testing.T) {
+	th := createMinimalHandWithBlind(t)
+	if err := playBlind(th.h, th.p1); err != nil {
+		t.Error(err)
+	}
+	if err := playRaise(th.h, th.p2, 3); err != nil {
+		t.Error(err)
+	}
+
+	got := th.h.State()
+
+	want := State{
+		Street:        Flop,
+		Board:         make([]Card, 3),
+		Pot:           smallBlind + 3,
+		Pots:          []Pot{{Amount: smallBlind + 3, Eligible: []string{th.p1.Id, th.p2.Id}}},
+		CurrentBet:    3,
+		Committed:     map[string]int{th.p1.Id: smallBlind, th.p2.Id: 3},
+		LastAggressor: th.p2.Id,
+		NextToPlay:    th.p1.Id,
+		TurnOrder:     []string{th.p1.Id, th.p2.Id},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v but got %+v", want, got)
+	}
+}
+
+func TestStateResetsCommitmentsOnNewStreet(t *testing.T) {
+	th := createMinimalHand(t)
+	if err := playRaise(th.h, th.p1, 2); err != nil {
+		t.Error(err)
+	}
+	if err := playCall(th.h, th.p2); err != nil {
+		t.Error(err)
+	}
+
+	got := th.h.State()
+
+	if got.Street != Turn {
+		t.Errorf("expected street %v but got %v", Turn, got.Street)
+	}
+	if got.Pot != 4 || got.CurrentBet != 0 || got.LastAggressor != "" {
+		t.Errorf("expected pot of 4 with no bet or aggressor but got %+v", got)
+	}
+	if got.Committed[th.p1.Id] != 0 || got.Committed[th.p2.Id] != 0 {
+		t.Errorf("expected no chips committed on new street but got %v", got.Committed)
+	}
+}
+
+func TestStateDividesSidePots(t *testing.T) {
+	p1 := NewPlayer("short", 4)
+	p2 := createPlayer()
+	p3 := createPlayer()
+	h, err := NewHand([]*Player{p1, p2, p3}, p1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Begin(context.Background())
+	if err := playRaise(h, p1, 4); err != nil {
+		t.Error(err)
+	}
+	if err := playRaise(h, p2, 8); err != nil {
+		t.Error(err)
+	}
+	if err := playCall(h, p3); err != nil {
+		t.Error(err)
+	}
+
+	got := h.State().Pots
+
+	want := []Pot{
+		{Amount: 12, Eligible: []string{p1.Id, p2.Id, p3.Id}},
+		{Amount: 8, Eligible: []string{p2.Id, p3.Id}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v but got %v", want, got)
+	}
+}
+
+func TestViewForHidesOpponentsCards(t *testing.T) {
+	th := createMinimalHandWithCards(t)
+
+	v, err := th.h.ViewFor(th.p1.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(v.Self.Cards, th.p1.Cards) {
+		t.Errorf("expected own cards %v but got %v", th.p1.Cards, v.Self.Cards)
+	}
+	if len(v.Opponents) != 1 || v.Opponents[0].Id != th.p2.Id {
+		t.Fatalf("expected single opponent %v but got %v", th.p2, v.Opponents)
+	}
+	if v.Opponents[0].Cards != nil || v.Opponents[0].FaceDown != 2 {
+		t.Errorf("expected opponent's two cards to be face down but got %+v", v.Opponents[0])
+	}
+	want := []Move{
+		NewMove(Fold, RequiredBet{}),
+		NewMove(Check, RequiredBet{}),
+		NewMove(Bet, NewBetRange(1, initial)),
+		NewMove(AllIn, NewExactBet(initial)),
+	}
+	if !reflect.DeepEqual(v.Moves, want) {
+		t.Errorf("expected moves %v but got %v", want, v.Moves)
+	}
+}
+
+func TestViewForOpponentHasNoMoves(t *testing.T) {
+	th := createMinimalHandWithCards(t)
+
+	v, err := th.h.ViewFor(th.p2.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(v.Moves) != 0 {
+		t.Errorf("expected no moves for player not next to play but got %v", v.Moves)
+	}
+}
+
+func TestViewForRevealsCardsShownAtShowdown(t *testing.T) {
+	th := createMinimalHandWithCards(t)
+	for i := 0; i < 3; i++ {
+		if err := playCheck(th.h, th.p1); err != nil {
+			t.Error(err)
+		}
+		if err := playCheck(th.h, th.p2); err != nil {
+			t.Error(err)
+		}
+	}
+	<-th.fin
+
+	v, err := th.h.ViewFor(th.p1.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(v.Opponents[0].Cards, th.p2.Cards) {
+		t.Errorf("expected opponent's cards %v to be shown but got %v", th.p2.Cards, v.Opponents[0].Cards)
+	}
+}
+
+func TestViewForHidesCardsOfWinnerWithoutShowdown(t *testing.T) {
+	th := createMinimalHandWithCards(t)
+	if err := playFold(th.h, th.p1); err != nil {
+		t.Error(err)
+	}
+
+	v, err := th.h.ViewFor(th.p1.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v.Opponents[0].Cards != nil {
+		t.Errorf("expected winner's cards to remain hidden but got %v", v.Opponents[0].Cards)
+	}
+	if !v.Self.Folded {
+		t.Error("expected viewer to have folded")
+	}
+}
+
+func TestViewForIsNotAffectedByLaterChanges(t *testing.T) {
+	th := createMinimalHandWithCards(t)
+	v, err := th.h.ViewFor(th.p1.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v.Self.Cards[0] = Card{}
+	if err := playRaise(th.h, th.p1, 2); err != nil {
+		t.Error(err)
+	}
+
+	if th.p1.Cards[0] == (Card{}) {
+		t.Error("expected changing the view not to change the player's cards")
+	}
+	if v.Self.Chips != initial || v.Pot != 0 {
+		t.Errorf("expected view to be unchanged by later play but got %+v", v)
+	}
+}
+
+func TestViewForPlayerNotInHandReturnsError(t *testing.T) {
+	th := createMinimalHand(t)
+
+	if _, err := th.h.ViewFor(createPlayer().Id); err == nil {
+		t.Error("expected error for player not in hand but none received")
+	}
+}
+
+func createMinimalHandWithCards(t *testing.T) testHand {
+	th := createMinimalHand(t)
+	th.p1.Cards = []Card{{Suit: Spades, Rank: Ace}, {Suit: Clubs, Rank: Ace}}
+	th.p2.Cards = []Card{{Suit: Hearts, Rank: King}, {Suit: Diamonds, Rank: King}}
+	return th
+}
+
+func TestSpectatorViewHidesAllCards(t *testing.T) {
+	th := createMinimalHandWithCards(t)
+
+	v := th.h.SpectatorView()
+
+	if len(v.Opponents) != 2 {
+		t.Fatalf("expected both players to be shown but got %v", v.Opponents)
+	}
+	for _, o := range v.Opponents {
+		if o.Cards != nil || o.FaceDown != 2 {
+			t.Errorf("expected cards of %s to be face down but got %+v", o.Id, o)
+		}
+	}
+	if len(v.Moves) != 0 {
+		t.Errorf("expected spectator to have no moves but got %v", v.Moves)
+	}
+}
+
+func TestBroadcastViewIsDelayedByActions(t *testing.T) {
+	th := createBroadcastHand(t, BroadcastDelay{Actions: 2})
+
+	if err := playRaise(th.h, th.p1, 2); err != nil {
+		t.Error(err)
+	}
+	v, err := th.h.BroadcastView()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertBroadcastHidesCards(t, v)
+
+	if err := playRaise(th.h, th.p2, 4); err != nil {
+		t.Error(err)
+	}
+	if err := playCall(th.h, th.p1); err != nil {
+		t.Error(err)
+	}
+	v, err = th.h.BroadcastView()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Pot != 2 {
+		t.Errorf("expected broadcast to show hand after first action but got pot of %d", v.Pot)
+	}
+	for _, o := range v.Opponents {
+		if o.Cards == nil {
+			t.Errorf("expected broadcast to reveal cards of %s", o.Id)
+		}
+	}
+}
+
+func TestBroadcastViewIsDelayedByDuration(t *testing.T) {
+	clock := newFakeClock()
+	th := createBroadcastHand(t, BroadcastDelay{Duration: time.Minute, Clock: clock})
+
+	if err := playRaise(th.h, th.p1, 2); err != nil {
+		t.Error(err)
+	}
+	clock.Advance(time.Minute - time.Second)
+	v, _ := th.h.BroadcastView()
+	assertBroadcastHidesCards(t, v)
+	if v.Pot != 0 {
+		t.Errorf("expected broadcast to show hand as it began until delay passes but got pot of %d", v.Pot)
+	}
+
+	clock.Advance(time.Second)
+	if v, _ := th.h.BroadcastView(); v.Pot != 2 {
+		t.Errorf("expected broadcast to show hand after first action but got pot of %d", v.Pot)
+	}
+}
+
+func TestBroadcastViewHidesCardsAsHandBegins(t *testing.T) {
+	clock := newFakeClock()
+	th := createBroadcastHand(t, BroadcastDelay{Duration: time.Minute, Clock: clock})
+
+	v, err := th.h.BroadcastView()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertBroadcastHidesCards(t, v)
+}
+
+func TestBroadcastViewBeforeHandBeginsIsEmpty(t *testing.T) {
+	p1 := createPlayer()
+	p2 := createPlayer()
+	h, err := NewHand([]*Player{p1, p2}, p1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.SetBroadcastDelay(BroadcastDelay{Actions: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := h.BroadcastView()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(v, View{}) {
+		t.Errorf("expected empty broadcast before hand begins but got %+v", v)
+	}
+}
+
+func TestBroadcastViewRequiresBothDelays(t *testing.T) {
+	clock := newFakeClock()
+	th := createBroadcastHand(t, BroadcastDelay{Actions: 1, Duration: time.Minute, Clock: clock})
+
+	if err := playRaise(th.h, th.p1, 2); err != nil {
+		t.Error(err)
+	}
+	v, _ := th.h.BroadcastView()
+	assertBroadcastHidesCards(t, v)
+
+	clock.Advance(time.Minute)
+	v, _ = th.h.BroadcastView()
+	if v.Pot != 0 {
+		t.Errorf("expected broadcast to show hand as it began but got pot of %d", v.Pot)
+	}
+	for _, o := range v.Opponents {
+		if o.Cards == nil {
+			t.Errorf("expected broadcast to reveal cards of %s", o.Id)
+		}
+	}
+}
+
+func assertBroadcastHidesCards(t *testing.T, v View) {
+	t.Helper()
+	for _, o := range v.Opponents {
+		if o.Cards != nil {
+			t.Errorf("expected broadcast to hide cards of %s but got %v", o.Id, o.Cards)
+		}
+	}
+}
+
+func TestBroadcastViewRequiresBroadcastDelay(t *testing.T) {
+	th := createMinimalHand(t)
+
+	if _, err := th.h.BroadcastView(); err == nil {
+		t.Error("expected error for hand not being broadcast but none received")
+	}
+}
+
+func createBroadcastHand(t *testing.T, d BroadcastDelay) testHand {
+	p1 := createPlayer()
+	p2 := createPlayer()
+	p1.Cards = []Card{{Suit: Spades, Rank: Ace}, {Suit: Clubs, Rank: Ace}}
+	p2.Cards = []Card{{Suit: Hearts, Rank: King}, {Suit: Diamonds, Rank: King}}
+	h, err := NewHand([]*Player{p1, p2}, p1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.SetBroadcastDelay(d); err != nil {
+		t.Fatal(err)
+	}
+	fin, err := h.Begin(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testHand{h, p1, p2, fin}
+}
+
+func TestNewHandWithInvalidRulesReturnsError(t *testing.T) {
+	tests := map[string]Rules{
+		"non-positive blind":      {Blinds: []int{smallBlind, 0}},
+		"more blinds than player": {Blinds: []int{1, 2, 3}},
+		"unknown variant":         {Variant: Variant(-1)},
+		"unknown limit":           {Limit: BettingLimit(7)},
+		"fixed limit no blinds":   {Limit: FixedLimit},
+		"negative ante":           {Ante: -1},
+		"straddle no big blind":   {Blinds: []int{smallBlind}, Straddle: MandatoryStraddle},
+		"rake over 100 percent":   {Rake: Rake{Percent: 101}},
+		"negative rake cap":       {Rake: Rake{Percent: 5, Cap: -1}},
+		"no time per action":      {TimeLimits: &TimeLimits{}},
+		"unknown odd chip rule":   {OddChip: OddChipRule(3)},
+	}
+	for name, rules := range tests {
+		t.Run(name, func(t *testing.T) {
+			p1 := createPlayer()
+			p2 := createPlayer()
+			if _, err := NewHandWithRules([]*Player{p1, p2}, p1, rules); err == nil {
+				t.Errorf("expected error for rules %+v but none received", rules)
+			}
+		})
+	}
+}
+
+func TestNewHandWithDealerNotInHandReturnsError(t *testing.T) {
+	p1 := createPlayer()
+	p2 := createPlayer()
+
+	if _, err := NewHand([]*Player{p1, p2}, createPlayer()); err == nil {
+		t.Error("expected error for dealer not in hand but none received")
+	}
+}
+
+func TestAntesArePostedWhenHandBegins(t *testing.T) {
+	p1 := createPlayer()
+	p2 := createPlayer()
+	h, err := NewHandWithRules([]*Player{p1, p2}, p1, Rules{Ante: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Begin(context.Background())
+
+	if p1.Chips != initial-1 || p2.Chips != initial-1 {
+		t.Errorf("expected both players to post ante but have %d and %d chips", p1.Chips, p2.Chips)
+	}
+	if req := h.pot.required(*p2); req != 0 {
+		t.Errorf("expected ante not to count towards bets but %d required", req)
+	}
+	if pot := h.State().Pot; pot != 2 {
+		t.Errorf("expected pot of 2 but got %d", pot)
+	}
+}
+
+func TestPlayerShortOfAnteIsAllIn(t *testing.T) {
+	p1 := NewPlayer("short", 1)
+	p2 := createPlayer()
+	p3 := createPlayer()
+	h, err := NewHandWithRules([]*Player{p1, p2, p3}, p1, Rules{Ante: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Begin(context.Background())
+
+	if p1.Chips != 0 {
+		t.Errorf("expected player to post ante of all their chips but has %d", p1.Chips)
+	}
+	if h.IsNextToPlay(p1.Id) {
+		t.Error("expected player all-in from ante not to act")
+	}
+	if err := playBet(h, p2, 4); err != nil {
+		t.Error(err)
+	}
+	if err := playCall(h, p3); err != nil {
+		t.Error(err)
+	}
+	want := []Pot{{Amount: 1 + 2 + 2, Eligible: []string{p1.Id, p2.Id, p3.Id}}, {Amount: 8, Eligible: []string{p2.Id, p3.Id}}}
+	if got := h.State().Pots; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected player all-in from ante to be eligible for the antes only but got %+v", got)
+	}
+}
+
+func TestStraddleIsPostedAfterBigBlind(t *testing.T) {
+	p1 := createPlayer()
+	p2 := createPlayer()
+	p3 := createPlayer()
+	rules := Rules{Blinds: []int{smallBlind, bigBlind}, Straddle: MandatoryStraddle}
+	h, err := NewHandWithRules([]*Player{p1, p2, p3}, p1, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Begin(context.Background())
+	if err := playBlind(h, p1); err != nil {
+		t.Error(err)
+	}
+	if err := playBlind(h, p2); err != nil {
+		t.Error(err)
+	}
+	if err := playBlind(h, p3); err != nil {
+		t.Error(err)
+	}
+
+	if p3.Chips != initial-2*bigBlind {
+		t.Errorf("expected straddle of %d but player has %d chips", 2*bigBlind, p3.Chips)
+	}
+}
+
+func TestRakeIsTakenFromPot(t *testing.T) {
+	p1 := createPlayer()
+	p2 := createPlayer()
+	h, err := NewHandWithRules([]*Player{p1, p2}, p1, Rules{Rake: Rake{Percent: 10, Cap: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fin, _ := h.Begin(context.Background())
+	if err := playRaise(h, p1, 5); err != nil {
+		t.Error(err)
+	}
+	if err := playRaise(h, p2, 10); err != nil {
+		t.Error(err)
+	}
+	if err := playFold(h, p1); err != nil {
+		t.Error(err)
+	}
+
+	want := FinishedHand{winner: p2, chips: 14}
+	if got := <-fin; got != want {
+		t.Errorf("expected %v but got %v", want, got)
+	}
+}
+
+func TestHandWithRandomSourceDealsCards(t *testing.T) {
+	p1 := createPlayer()
+	p2 := createPlayer()
+	rules := Rules{Blinds: []int{smallBlind}, Variant: Omaha, Rand: rand.New(rand.NewSource(1))}
+	h, err := NewHandWithRules([]*Player{p1, p2}, p1, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Begin(context.Background())
+	if err := playBlind(h, p1); err != nil {
+		t.Error(err)
+	}
+
+	seen := make(map[Card]bool)
+	for _, v := range append(append(append([]Card{}, p1.Cards...), p2.Cards...), h.Cards...) {
+		if v == (Card{}) || seen[v] {
+			t.Errorf("expected distinct cards to be dealt but got %v", v)
+		}
+		seen[v] = true
+	}
+	if len(p1.Cards) != 4 || len(p2.Cards) != 4 || len(h.Cards) != 3 {
+		t.Errorf("expected four hole cards each and three on the board but got %v, %v and %v", p1.Cards, p2.Cards, h.Cards)
+	}
+}
+
+func TestPlayingOutOfTurnReturnsOutOfTurnError(t *testing.T) {
+	th := createMinimalHand(t)
+
+	err := playCheck(th.h, th.p2)
+
+	if !errors.Is(err, ErrOutOfTurn) {
+		t.Errorf("expected %v but got %v", ErrOutOfTurn, err)
+	}
+	var oot *OutOfTurnError
+	if !errors.As(err, &oot) || oot.PlayerId != th.p2.Id || oot.NextToPlayId != th.p1.Id {
+		t.Errorf("expected out of turn error for %s but got %v", th.p2.Id, err)
+	}
+}
+
+func TestCheckingWhenBetIsDueReturnsActionError(t *testing.T) {
+	th := createMinimalHand(t)
+	if err := playRaise(th.h, th.p1, 2); err != nil {
+		t.Error(err)
+	}
+
+	err := playCheck(th.h, th.p2)
+
+	var ae *ActionError
+	if !errors.As(err, &ae) || !errors.Is(err, ErrInvalidAction) {
+		t.Fatalf("expected action error but got %v", err)
+	}
+	want := ActionError{PlayerId: th.p2.Id, Action: Check, Street: Flop, Reason: "2 required to call"}
+	if *ae != want {
+		t.Errorf("expected %v but got %v", want, *ae)
+	}
+}
+
+func TestPlayingWrongBlindReturnsBetError(t *testing.T) {
+	th := createMinimalHandWithBlind(t)
+
+	err := th.h.PlayBlind(th.p1.Id, smallBlind+1)
+
+	var be *BetError
+	if !errors.As(err, &be) || !errors.Is(err, ErrInvalidBet) {
+		t.Fatalf("expected bet error but got %v", err)
+	}
+	want := BetError{PlayerId: th.p1.Id, Action: Blind, Chips: smallBlind + 1, Allowed: NewExactBet(smallBlind)}
+	if *be != want {
+		t.Errorf("expected %v but got %v", want, *be)
+	}
+}
+
+func TestPlayingAfterHandIsWonReturnsHandFinished(t *testing.T) {
+	th := createMinimalHand(t)
+	if err := playFold(th.h, th.p1); err != nil {
+		t.Error(err)
+	}
+
+	if err := playCheck(th.h, th.h.nextToPlay); !errors.Is(err, ErrHandFinished) {
+		t.Errorf("expected %v but got %v", ErrHandFinished, err)
+	}
+}
+
+func TestInvalidRulesWrapInvalidRulesError(t *testing.T) {
+	p1 := createPlayer()
+	p2 := createPlayer()
+
+	_, err := NewHandWithRules([]*Player{p1, p2}, p1, Rules{Ante: -1})
+
+	if !errors.Is(err, ErrInvalidRules) {
+		t.Errorf("expected %v but got %v", ErrInvalidRules, err)
+	}
+}
+
+func TestPlayerNotFoundReturnsPlayerNotFoundError(t *testing.T) {
+	th := createMinimalHand(t)
+
+	if err := th.h.PlayBlind(createPlayer().Id, 1); !errors.Is(err, ErrPlayerNotFound) {
+		t.Errorf("expected %v but got %v", ErrPlayerNotFound, err)
+	}
+}
+
+func TestUndoRestoresStateBeforeMove(t *testing.T) {
+	th := createMinimalHand(t)
+	if err := playRaise(th.h, th.p1, 2); err != nil {
+		t.Error(err)
+	}
+	if err := playRaise(th.h, th.p2, 5); err != nil {
+		t.Error(err)
+	}
+
+	if err := th.h.Undo(1, "misclick"); err != nil {
+		t.Fatal(err)
+	}
+
+	if th.p2.Chips != initial {
+		t.Errorf("expected player to have %d chips restored but has %d", initial, th.p2.Chips)
+	}
+	if !th.h.IsNextToPlay(th.p2.Id) {
+		t.Error("expected player whose move was undone to be next to play")
+	}
+	s := th.h.State()
+	if s.Pot != 2 || s.LastAggressor != th.p1.Id {
+		t.Errorf("expected pot of 2 with first player as aggressor but got %+v", s)
+	}
+	if err := playRaise(th.h, th.p2, 4); err != nil {
+		t.Error(err)
+	}
+	if th.p2.Chips != initial-4 {
+		t.Errorf("expected corrected raise to leave %d chips but has %d", initial-4, th.p2.Chips)
+	}
+}
+
+func TestUndoMultipleMovesRecordsEachCorrection(t *testing.T) {
+	p1 := createPlayer()
+	p2 := createPlayer()
+	p3 := createPlayer()
+	h, err := NewHand([]*Player{p1, p2, p3}, p1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Begin(context.Background())
+	if err := playRaise(h, p1, 2); err != nil {
+		t.Error(err)
+	}
+	if err := playFold(h, p2); err != nil {
+		t.Error(err)
+	}
+
+	if err := h.Undo(2, "dealt in by mistake"); err != nil {
+		t.Fatal(err)
+	}
+
+	if p1.Chips != initial || p2.Folded {
+		t.Errorf("expected raise and fold to be undone but got %d chips and folded %v", p1.Chips, p2.Folded)
+	}
+	checkPlayers(t, h.players, p1, p2, p3)
+	want := []Entry{
+		{Seq: 1, PlayerId: p1.Id, Street: Flop, Input: Input{Action: Raise, Chips: 2}},
+		{Seq: 2, PlayerId: p2.Id, Street: Flop, Input: Input{Action: Fold}},
+		{Seq: 3, PlayerId: p2.Id, Street: Flop, Input: Input{Action: Fold}, Corrects: 2, Note: "dealt in by mistake"},
+		{Seq: 4, PlayerId: p1.Id, Street: Flop, Input: Input{Action: Raise, Chips: 2}, Corrects: 1, Note: "dealt in by mistake"},
+	}
+	if got := h.History(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v but got %v", want, got)
+	}
+}
+
+func TestUndoCannotCrossStreets(t *testing.T) {
+	th := createMinimalHand(t)
+	if err := playCheck(th.h, th.p1); err != nil {
+		t.Error(err)
+	}
+	if err := playCheck(th.h, th.p2); err != nil {
+		t.Error(err)
+	}
+
+	if err := th.h.Undo(1, "too late"); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("expected %v but got %v", ErrNothingToUndo, err)
+	}
+}
+
+func TestUndoFirstPreflopActionKeepsBlinds(t *testing.T) {
+	p1 := createPlayer()
+	p2 := createPlayer()
+	h, err := NewHandWithRules([]*Player{p1, p2}, p1, Rules{Blinds: []int{smallBlind, bigBlind}, PreflopBetting: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Begin(context.Background())
+	playBlind(h, p1)
+	playBlind(h, p2)
+	if err := playCall(h, p1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h.Undo(2, "too far"); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("expected %v undoing a blind but got %v", ErrNothingToUndo, err)
+	}
+	if err := h.Undo(1, "misclick"); err != nil {
+		t.Fatal(err)
+	}
+
+	if p1.Chips != initial-smallBlind || p2.Chips != initial-bigBlind {
+		t.Errorf("expected blinds to stay posted but got %d and %d chips", p1.Chips, p2.Chips)
+	}
+	moves := h.ValidMoves()[p1.Id]
+	if _, ok := findMove(moves, Call); !ok || !h.IsNextToPlay(p1.Id) {
+		t.Fatalf("expected small blind to be asked to call again but got %v", moves)
+	}
+	if err := playCall(h, p1); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUndoAfterHandIsWonReturnsError(t *testing.T) {
+	th := createMinimalHand(t)
+	if err := playFold(th.h, th.p1); err != nil {
+		t.Error(err)
+	}
+
+	if err := th.h.Undo(1, "too late"); !errors.Is(err, ErrHandFinished) {
+		t.Errorf("expected %v but got %v", ErrHandFinished, err)
+	}
+}
+
+func TestCancelRefundsContributions(t *testing.T) {
+	p1 := createPlayer()
+	p2 := createPlayer()
+	h, err := NewHandWithRules([]*Player{p1, p2}, p1, Rules{Blinds: []int{smallBlind}, Ante: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fin, _ := h.Begin(context.Background())
+	if err := playBlind(h, p1); err != nil {
+		t.Error(err)
+	}
+	if err := playRaise(h, p2, 4); err != nil {
+		t.Error(err)
+	}
+
+	if err := h.Cancel("misdeal"); err != nil {
+		t.Fatal(err)
+	}
+
+	if p1.Chips != initial || p2.Chips != initial {
+		t.Errorf("expected chips to be refunded but players have %d and %d", p1.Chips, p2.Chips)
+	}
+	want := FinishedHand{cancelled: true, reason: "misdeal"}
+	if got := <-fin; got != want {
+		t.Errorf("expected %v but got %v", want, got)
+	}
+	if _, ok := <-fin; ok {
+		t.Error("expected done channel to be closed")
+	}
+	hist := h.History()
+	if last := hist[len(hist)-1]; last.Note != "cancelled: misdeal" {
+		t.Errorf("expected cancellation to be recorded but got %v", last)
+	}
+	if err := playCheck(h, p1); !errors.Is(err, ErrHandFinished) {
+		t.Errorf("expected %v but got %v", ErrHandFinished, err)
+	}
+}
+
+func TestCancellingContextCancelsHand(t *testing.T) {
+	p1 := createPlayer()
+	p2 := createPlayer()
+	h, err := NewHand([]*Player{p1, p2}, p1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	fin, _ := h.Begin(ctx)
+	if err := playRaise(h, p1, 2); err != nil {
+		t.Error(err)
+	}
+
+	cancel()
+
+	select {
+	case got := <-fin:
+		if !got.Cancelled() || got.Reason() != context.Canceled.Error() {
+			t.Errorf("expected hand to be cancelled but got %v", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for hand to be cancelled")
+	}
+	if p1.Chips != initial {
+		t.Errorf("expected chips to be refunded but player has %d", p1.Chips)
+	}
+}
+
+func TestCancelAfterHandIsWonReturnsError(t *testing.T) {
+	th := createMinimalHand(t)
+	if err := playFold(th.h, th.p1); err != nil {
+		t.Error(err)
+	}
+
+	if err := th.h.Cancel("too late"); !errors.Is(err, ErrHandFinished) {
+		t.Errorf("expected %v but got %v", ErrHandFinished, err)
+	}
+}
+
+func TestBeginAfterCancelReturnsError(t *testing.T) {
+	p1 := createPlayer()
+	p2 := createPlayer()
+	h, err := NewHand([]*Player{p1, p2}, p1, smallBlind, bigBlind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Cancel("misdeal"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := h.Begin(context.Background()); !errors.Is(err, ErrHandFinished) {
+		t.Errorf("expected %v but got %v", ErrHandFinished, err)
+	}
+	if p1.Chips != initial || p2.Chips != initial {
+		t.Errorf("expected no blinds to be posted but got %d and %d chips", p1.Chips, p2.Chips)
+	}
+}
+
+func TestBetOpensBetting(t *testing.T) {
+	th := createMinimalHand(t)
+
+	if err := playBet(th.h, th.p1, 3); err != nil {
+		t.Error(err)
+	}
+
+	if th.p1.Chips != initial-3 {
+		t.Errorf("expected player to have %d chips after betting but has %d", initial-3, th.p1.Chips)
+	}
+	if s := th.h.State(); s.CurrentBet != 3 || s.LastAggressor != th.p1.Id {
+		t.Errorf("expected bet of 3 by %s but got %+v", th.p1.Id, s)
+	}
+}
+
+func TestBetWhenBettingIsOpenReturnsError(t *testing.T) {
+	th := createMinimalHand(t)
+	if err := playBet(th.h, th.p1, 3); err != nil {
+		t.Error(err)
+	}
+
+	if err := playBet(th.h, th.p2, 5); !errors.Is(err, ErrInvalidAction) {
+		t.Errorf("expected %v but got %v", ErrInvalidAction, err)
+	}
+}
+
+func TestBetBelowBigBlindReturnsError(t *testing.T) {
+	p1 := createPlayer()
+	p2 := createPlayer()
+	h, err := NewHand([]*Player{p1, p2}, p1, smallBlind, bigBlind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Begin(context.Background())
+	playBlind(h, p1)
+	playBlind(h, p2)
+	playCall(h, p1)
+	playCheck(h, p2)
+
+	// heads up the big blind acts first after the flop
+	err = playBet(h, p2, bigBlind-1)
+
+	var be *BetError
+	if !errors.As(err, &be) || be.Allowed != NewBetRange(bigBlind, initial-bigBlind) {
+		t.Errorf("expected bet of at least %d but got %v", bigBlind, err)
+	}
+}
+
+func TestValidMovesAfterBlindsOfferRaise(t *testing.T) {
+	p1 := createPlayer()
+	p2 := createPlayer()
+	h, err := NewHand([]*Player{p1, p2}, p1, smallBlind, bigBlind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Begin(context.Background())
+	playBlind(h, p1)
+	playBlind(h, p2)
+	playCall(h, p1)
+
+	got := h.ValidMoves()[p2.Id]
+
+	want := []Move{
+		NewMove(Fold, RequiredBet{}),
+		NewMove(Check, RequiredBet{}),
+		NewMove(Raise, NewBetRange(2*bigBlind, initial)),
+		NewMove(AllIn, NewExactBet(initial-bigBlind)),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v but got %v", want, got)
+	}
+}
+
+func TestRaiseIsTotalForStreet(t *testing.T) {
+	p1 := createPlayer()
+	p2 := createPlayer()
+	h, err := NewHand([]*Player{p1, p2}, p1, smallBlind, bigBlind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Begin(context.Background())
+	playBlind(h, p1)
+	playBlind(h, p2)
+
+	if err := playRaise(h, p1, 3*bigBlind); err != nil {
+		t.Fatal(err)
+	}
+	if p1.Chips != initial-3*bigBlind {
+		t.Errorf("expected raise to %d to leave %d chips but has %d", 3*bigBlind, initial-3*bigBlind, p1.Chips)
+	}
+	var be *BetError
+	if err := playRaise(h, p2, 4*bigBlind); !errors.As(err, &be) || be.Allowed != NewBetRange(5*bigBlind, initial) {
+		t.Errorf("expected re-raise of at least the previous raise but got %v", err)
+	}
+}
+
+func TestRaiseIncrementIsChipsAdded(t *testing.T) {
+	p1 := createPlayer()
+	p2 := createPlayer()
+	h, err := NewHandWithRules([]*Player{p1, p2}, p1, Rules{Blinds: []int{smallBlind, bigBlind}, Raises: RaiseIncrement})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Begin(context.Background())
+	playBlind(h, p1)
+	playBlind(h, p2)
+
+	want := NewMove(Raise, NewBetRange(2*bigBlind-smallBlind, initial-smallBlind))
+	if got := h.ValidMoves()[p1.Id][2]; got != want {
+		t.Errorf("expected %v but got %v", want, got)
+	}
+	if err := playRaise(h, p1, 2*bigBlind-smallBlind); err != nil {
+		t.Fatal(err)
+	}
+	if p1.Chips != initial-2*bigBlind {
+		t.Errorf("expected %d chips but has %d", initial-2*bigBlind, p1.Chips)
+	}
+}
+
+func TestPotLimitCapsRaiseAtPot(t *testing.T) {
+	p1 := NewPlayer("deep", 100)
+	p2 := NewPlayer("deep", 100)
+	h, err := NewHandWithRules([]*Player{p1, p2}, p1, Rules{Blinds: []int{smallBlind, bigBlind}, Limit: PotLimit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Begin(context.Background())
+	playBlind(h, p1)
+	playBlind(h, p2)
+
+	// the pot after calling is twice the big blind, so the largest raise is to three times it
+	var be *BetError
+	if err := playRaise(h, p1, 3*bigBlind+1); !errors.As(err, &be) || be.Allowed != NewBetRange(2*bigBlind, 3*bigBlind) {
+		t.Errorf("expected raise limited to the pot but got %v", err)
+	}
+}
+
+func TestAllInBelowRequiredIsAllowed(t *testing.T) {
+	p1 := createPlayer()
+	p2 := NewPlayer("short", 3)
+	h, err := NewHand([]*Player{p1, p2}, p1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fin, _ := h.Begin(context.Background())
+	if err := playBet(h, p1, 5); err != nil {
+		t.Error(err)
+	}
+
+	if err := playAllIn(h, p2); err != nil {
+		t.Error(err)
+	}
+
+	if p2.Chips != 0 {
+		t.Errorf("expected player to have no chips after going all-in but has %d", p2.Chips)
+	}
+	if s := h.State(); s.Street != Showdown {
+		t.Errorf("expected hand to run out to showdown but is on the %v", s.Street)
+	}
+	if got := <-fin; got.Chips() != 8 {
+		t.Errorf("expected pot of 8 but got %v", got)
+	}
+}
+
+func TestCallWithFewerChipsThanRequiredGoesAllIn(t *testing.T) {
+	p1 := createPlayer()
+	p2 := NewPlayer("short", 3)
+	h, err := NewHand([]*Player{p1, p2}, p1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Begin(context.Background())
+	if err := playBet(h, p1, 5); err != nil {
+		t.Error(err)
+	}
+
+	if got := h.ValidMoves()[p2.Id]; !reflect.DeepEqual(got[1], NewMove(Call, NewExactBet(3))) {
+		t.Errorf("expected call all-in for 3 but got %v", got)
+	}
+	if err := playCall(h, p2); err != nil {
+		t.Error(err)
+	}
+	if p2.Chips != 0 {
+		t.Errorf("expected player to have no chips after calling all-in but has %d", p2.Chips)
+	}
+}
+
+func TestAllInPlayerIsSkippedInTurnOrder(t *testing.T) {
+	p1 := NewPlayer("short", 3)
+	p2 := createPlayer()
+	p3 := createPlayer()
+	h, err := NewHand([]*Player{p1, p2, p3}, p1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Begin(context.Background())
+	if err := playAllIn(h, p1); err != nil {
+		t.Error(err)
+	}
+	if err := playCall(h, p2); err != nil {
+		t.Error(err)
+	}
+	if err := playCall(h, p3); err != nil {
+		t.Error(err)
+	}
+
+	if s := h.State(); s.Street != Turn || s.NextToPlay != p2.Id {
+		t.Errorf("expected %s to be first to play on the turn but got %+v", p2.Id, s)
+	}
+}
+
+func TestPlayPassesOverFoldedDealer(t *testing.T) {
+	p1 := createPlayer()
+	p2 := createPlayer()
+	p3 := createPlayer()
+	h, err := NewHand([]*Player{p1, p2, p3}, p1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Begin(context.Background())
+	if err := playFold(h, p1); err != nil {
+		t.Error(err)
+	}
+	if !h.IsNextToPlay(p2.Id) {
+		t.Error("expected play to pass to next player after dealer folds")
+	}
+	playCheck(h, p2)
+	playCheck(h, p3)
+
+	if !h.IsNextToPlay(p2.Id) {
+		t.Error("expected first player after folded dealer to play first on next street")
+	}
+}
+
+func playBet(h *Hand, p *Player, amount int) error {
+	return h.HandleInput(p, Input{Action: Bet, Chips: amount})
+}
+
+func playAllIn(h *Hand, p *Player) error {
+	return h.HandleInput(p, Input{Action: AllIn})
+}
+
+func TestHandAtSeatsPlaysClockwiseOverOccupiedSeats(t *testing.T) {
+	seats, err := NewSeats(9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2 := createPlayer()
+	p5 := createPlayer()
+	p8 := createPlayer()
+	for seat, p := range map[int]*Player{2: p2, 5: p5, 8: p8} {
+		if err := seats.Sit(seat, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h, err := NewHandAtSeats(seats, 5, Rules{Blinds: []int{smallBlind, bigBlind}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Begin(context.Background())
+
+	if !h.IsNextToPlay(p5.Id) {
+		t.Fatal("expected player on the button to post the first blind")
+	}
+	playBlind(h, p5)
+	if !h.IsNextToPlay(p8.Id) {
+		t.Error("expected next occupied seat clockwise to post the second blind")
+	}
+	v, err := h.ViewFor(p2.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Self.Seat != 2 || v.Button != 5 {
+		t.Errorf("expected seat 2 with button in seat 5 but got seat %d and button %d", v.Self.Seat, v.Button)
+	}
+}
+
+func TestSitInOccupiedSeatReturnsError(t *testing.T) {
+	seats, _ := NewSeats(2)
+	if err := seats.Sit(1, createPlayer()); err != nil {
+		t.Fatal(err)
+	}
+	if err := seats.Sit(1, createPlayer()); !errors.Is(err, ErrSeatTaken) {
+		t.Errorf("expected %v but got %v", ErrSeatTaken, err)
+	}
+	if err := seats.Sit(3, createPlayer()); !errors.Is(err, ErrInvalidSeat) {
+		t.Errorf("expected %v but got %v", ErrInvalidSeat, err)
+	}
+	if seat, err := seats.SitAnywhere(createPlayer()); err != nil || seat != 2 {
+		t.Errorf("expected to sit in empty seat 2 but got %d, %v", seat, err)
+	}
+	if _, err := seats.SitAnywhere(createPlayer()); !errors.Is(err, ErrTableFull) {
+		t.Errorf("expected %v but got %v", ErrTableFull, err)
+	}
+}
+
+func createTable(t *testing.T, n int) (*Table, []*Player) {
+	seats, err := NewSeats(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps := make([]*Player, n)
+	for i := range ps {
+		ps[i] = createPlayer()
+		if err := seats.Sit(i+1, ps[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewTable(seats, Rules{Blinds: []int{smallBlind, bigBlind}}), ps
+}
+
+func nextHand(t *testing.T, tbl *Table) *Hand {
+	h, err := tbl.NextHand()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Begin(context.Background())
+	return h
+}
+
+func TestTableMovesButtonToEmptySeatOfSmallBlind(t *testing.T) {
+	tbl, ps := createTable(t, 4)
+	h := nextHand(t, tbl)
+	if tbl.Button() != 1 || !reflect.DeepEqual(tbl.Blinds(), []int{2, 3}) {
+		t.Fatalf("expected button in seat 1 with blinds in 2 and 3 but got %d and %v", tbl.Button(), tbl.Blinds())
+	}
+	h.Cancel("next hand")
+	tbl.Seats.Leave(ps[1].Id)
+
+	h = nextHand(t, tbl)
+
+	if tbl.Button() != 2 || !reflect.DeepEqual(tbl.Blinds(), []int{3, 4}) {
+		t.Errorf("expected dead button in seat 2 with blinds in 3 and 4 but got %d and %v", tbl.Button(), tbl.Blinds())
+	}
+	if !h.IsNextToPlay(ps[2].Id) {
+		t.Error("expected player in seat 3 to post the small blind")
+	}
+}
+
+func TestTableLeavesSmallBlindDeadWhenBigBlindLeaves(t *testing.T) {
+	tbl, ps := createTable(t, 4)
+	nextHand(t, tbl).Cancel("next hand")
+	tbl.Seats.Leave(ps[2].Id)
+
+	h := nextHand(t, tbl)
+
+	if !reflect.DeepEqual(tbl.Blinds(), []int{0, 4}) {
+		t.Errorf("expected dead small blind with big blind in seat 4 but got %v", tbl.Blinds())
+	}
+	if err := h.PlayBlind(ps[3].Id, smallBlind); !errors.Is(err, ErrInvalidBet) {
+		t.Errorf("expected only the big blind to be posted but got %v", err)
+	}
+	if err := h.PlayBlind(ps[3].Id, bigBlind); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTableChargesBlindsMissedWhileSittingOut(t *testing.T) {
+	tbl, ps := createTable(t, 4)
+	nextHand(t, tbl).Cancel("next hand")
+	ps[3].SittingOut = true
+
+	nextHand(t, tbl).Cancel("next hand")
+
+	if got := tbl.MissedBlinds(4); got != smallBlind+bigBlind {
+		t.Fatalf("expected both blinds to be missed but got %d", got)
+	}
+	if !reflect.DeepEqual(tbl.Blinds(), []int{3, 1}) {
+		t.Errorf("expected big blind to pass the player sitting out but got %v", tbl.Blinds())
+	}
+	ps[3].SittingOut = false
+
+	h := nextHand(t, tbl)
+
+	if got := h.State().Committed[ps[3].Id]; got != bigBlind {
+		t.Errorf("expected returning player to post the big blind live but committed %d", got)
+	}
+	if want := initial - smallBlind - bigBlind; ps[3].Chips != want {
+		t.Errorf("expected returning player to post all missed blinds leaving %d chips but has %d", want, ps[3].Chips)
+	}
+}
+
+func TestTableKeepsChipsFromHandToHand(t *testing.T) {
+	tbl, ps := createTable(t, 4)
+	tbl.Rules.PreflopBetting = true
+	tbl.Rules.Rand = rand.New(rand.NewSource(1))
+	bots := make(map[string]Strategy)
+	for i, s := range randomBots(len(ps), 1) {
+		bots[ps[i].Id] = s
+	}
+
+	for i := 0; i < 20; i++ {
+		h, err := tbl.NextHand()
+		if errors.Is(err, ErrNotEnoughPlayers) {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		fin, _ := h.Begin(context.Background())
+		if err := h.PlayBots(bots); err != nil {
+			t.Fatal(err)
+		}
+		<-fin
+
+		total := 0
+		for _, p := range ps {
+			total += p.Chips
+		}
+		if total != len(ps)*initial {
+			t.Fatalf("expected %d chips at the table after hand %d but got %d", len(ps)*initial, i, total)
+		}
+	}
+}
+
+func cards(cs ...string) []Card {
+	var out []Card
+	for _, c := range cs {
+		card, err := ParseCard(c)
+		if err != nil {
+			panic(err)
+		}
+		out = append(out, card)
+	}
+	return out
+}
+
+func TestEvaluateRanksHands(t *testing.T) {
+	tests := []struct {
+		cards    []Card
+		category HandCategory
+	}{
+		{cards("2c", "3c", "4c", "5c", "Ac", "Kd", "Kh"), StraightFlush},
+		{cards("9s", "9d", "9h", "9c", "2d"), FourOfAKind},
+		{cards("Ks", "Kd", "2h", "2c", "2d", "7s"), FullHouse},
+		{cards("2h", "7h", "9h", "Jh", "Ah", "Kc"), Flush},
+		{cards("As", "2d", "3h", "4c", "5d"), Straight},
+		{cards("Qs", "Qd", "Qh", "4c", "5d"), ThreeOfAKind},
+		{cards("Qs", "Qd", "4h", "4c", "5d"), TwoPair},
+		{cards("Qs", "Qd", "3h", "4c", "5d"), Pair},
+		{cards("Qs", "8d", "3h", "4c", "5d"), HighCard},
+	}
+	for _, tt := range tests {
+		if got := Evaluate(tt.cards).Category(); got != tt.category {
+			t.Errorf("expected %v to be %v but got %v", tt.cards, tt.category, got)
+		}
+	}
+
+	wheel := Evaluate(cards("As", "2d", "3h", "4c", "5d"))
+	six := Evaluate(cards("6s", "2d", "3h", "4c", "5d"))
+	if wheel >= six {
+		t.Error("expected six high straight to beat the wheel")
+	}
+	kicker := Evaluate(cards("Qs", "Qd", "4h", "4c", "Kd"))
+	if kicker <= Evaluate(cards("Qs", "Qd", "4h", "4c", "Jd")) {
+		t.Error("expected two pair to be decided by the kicker")
+	}
+	if Evaluate(cards("Qs", "Qd", "4h")) != 0 {
+		t.Error("expected fewer than five cards not to be ranked")
+	}
+}
+
+func TestEvaluateOmahaUsesTwoHoleCards(t *testing.T) {
+	hole := cards("Ah", "Kh", "Qh", "Jh")
+	board := cards("Th", "2c", "3d", "7s", "8s")
+
+	if got := EvaluateOmaha(hole, board).Category(); got != HighCard {
+		t.Errorf("expected only two hearts to play but got %v", got)
+	}
+}
+
+func TestShowdownSplitsPotBetweenTiedHands(t *testing.T) {
+	p1 := NewPlayer("one", 5)
+	p2 := NewPlayer("two", 5)
+	h, err := NewHand([]*Player{p1, p2}, p1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fin, _ := h.Begin(context.Background())
+	p1.Cards = cards("2c", "3d")
+	p2.Cards = cards("2h", "3s")
+	h.Cards = cards("As", "Ks", "Qd", "Jc", "Th")
+
+	playAllIn(h, p1)
+	playCall(h, p2)
+
+	got := <-fin
+	if want := map[string]int{p1.Id: 5, p2.Id: 5}; !reflect.DeepEqual(got.Awards(), want) {
+		t.Errorf("expected split pot %v but got %v", want, got.Awards())
+	}
+	if len(got.Runs()) != 1 || got.Runs()[0].Hands[p1.Id].Category() != Straight {
+		t.Errorf("expected a single run with straights but got %v", got.Runs())
+	}
+}
+
+func TestRunItTwiceDealsBoardAgainAndSplitsPot(t *testing.T) {
+	p1 := NewPlayer("one", 5)
+	p2 := NewPlayer("two", 5)
+	rules := Rules{Blinds: []int{smallBlind}, MaxRuns: 2, Rand: rand.New(rand.NewSource(1))}
+	h, err := NewHandWithRules([]*Player{p1, p2}, p1, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fin, _ := h.Begin(context.Background())
+	for _, p := range []*Player{p1, p2} {
+		if err := h.RunItTimes(p.Id, 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := playBlind(h, p1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := playAllIn(h, p2); err != nil {
+		t.Fatal(err)
+	}
+	if err := playCall(h, p1); err != nil {
+		t.Fatal(err)
+	}
+
+	got := <-fin
+	runs := got.Runs()
+	if len(runs) != 2 {
+		t.Fatalf("expected board to be run twice but got %v", runs)
+	}
+	if !reflect.DeepEqual(runs[0].Board[:3], runs[1].Board[:3]) || reflect.DeepEqual(runs[0].Board, runs[1].Board) {
+		t.Errorf("expected runs to share the flop only but got %v and %v", runs[0].Board, runs[1].Board)
+	}
+	total := 0
+	for i, r := range runs {
+		chips := 0
+		for _, v := range r.Awards {
+			chips += v
+		}
+		if chips != 5 {
+			t.Errorf("expected run %d to award half the pot but awarded %d", i, chips)
+		}
+		total += chips
+	}
+	if total != got.Chips() {
+		t.Errorf("expected %d chips awarded but got %d", total, got.Chips())
+	}
+}
+
+func TestRunItTimesAboveMaximumReturnsError(t *testing.T) {
+	th := createMinimalHand(t)
+
+	if err := th.h.RunItTimes(th.p1.Id, 2); !errors.Is(err, ErrInvalidAction) {
+		t.Errorf("expected %v but got %v", ErrInvalidAction, err)
+	}
+}
+
+func TestBotsPlayHandToCompletion(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		p1 := createPlayer()
+		p2 := createPlayer()
+		p3 := createPlayer()
+		h, err := NewHandWithRules([]*Player{p1, p2, p3}, p1, Rules{Blinds: []int{smallBlind, bigBlind}, Rand: r})
+		if err != nil {
+			t.Fatal(err)
+		}
+		fin, _ := h.Begin(context.Background())
+		bots := map[string]Strategy{p1.Id: NewRandomStrategy(r), p2.Id: CallingStation{}, p3.Id: TightAggressive{}}
+
+		if err := h.PlayBots(bots); err != nil {
+			t.Fatalf("expected bots to play valid moves but got %v", err)
+		}
+		select {
+		case <-fin:
+		default:
+			t.Fatal("expected bots to finish the hand")
+		}
+	}
+}
+
+func TestPlayStrategyOutOfTurnDoesNotDecide(t *testing.T) {
+	th := createMinimalHand(t)
+	decided := false
+	s := decideFunc(func(View, []Move) Input {
+		decided = true
+		return Input{Action: Fold}
+	})
+
+	if err := th.h.PlayStrategy(th.p2.Id, s); !errors.Is(err, ErrOutOfTurn) {
+		t.Errorf("expected %v but got %v", ErrOutOfTurn, err)
+	}
+	if decided {
+		t.Error("expected strategy not to be asked to decide out of turn")
+	}
+}
+
+func TestPlayStrategyDoesNotPlayDecisionOvertakenByHand(t *testing.T) {
+	th := createMinimalHand(t)
+	s := decideFunc(func(View, []Move) Input {
+		// the hand is not locked while deciding, so it may move on and come back to the player
+		if err := playCheck(th.h, th.p1); err != nil {
+			t.Error(err)
+		}
+		if err := th.h.Undo(1, "misclick"); err != nil {
+			t.Error(err)
+		}
+		return Input{Action: Fold}
+	})
+
+	if err := th.h.PlayStrategy(th.p1.Id, s); !errors.Is(err, ErrStaleDecision) {
+		t.Errorf("expected %v but got %v", ErrStaleDecision, err)
+	}
+	if th.p1.Folded || !th.h.IsNextToPlay(th.p1.Id) {
+		t.Error("expected decision on the hand as it was not to be played")
+	}
+}
+
+func TestTightAggressiveRaisesStrongAndFoldsWeakHands(t *testing.T) {
+	moves := []Move{
+		NewMove(Fold, RequiredBet{}),
+		NewMove(Call, NewExactBet(4)),
+		NewMove(Raise, NewBetRange(8, 100)),
+		NewMove(AllIn, NewExactBet(100)),
+	}
+	strong := View{Self: SeatView{Cards: cards("As", "Ad")}, Pot: 6}
+	weak := View{Self: SeatView{Cards: cards("7s", "2d")}, Pot: 6}
+
+	if got := (TightAggressive{}).Decide(strong, moves); got != (Input{Action: Raise, Chips: 8}) {
+		t.Errorf("expected minimum raise with aces but got %v", got)
+	}
+	if got := (TightAggressive{}).Decide(weak, moves); got.Action != Fold {
+		t.Errorf("expected fold with seven two but got %v", got)
+	}
+}
+
+func TestPreflopBettingPrecedesFlop(t *testing.T) {
+	p1 := createPlayer()
+	p2 := createPlayer()
+	rules := Rules{Blinds: []int{smallBlind, bigBlind}, PreflopBetting: true, Rand: rand.New(rand.NewSource(1))}
+	h, err := NewHandWithRules([]*Player{p1, p2}, p1, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Begin(context.Background())
+	playBlind(h, p1)
+	playBlind(h, p2)
+
+	if s := h.State(); s.Street != Preflop || len(s.Board) != 0 || !h.IsNextToPlay(p1.Id) {
+		t.Fatalf("expected the small blind to act first before the flop but got %+v", s)
+	}
+	if err := playCall(h, p1); err != nil {
+		t.Fatal(err)
+	}
+	if err := playCheck(h, p2); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := h.State(); s.Street != Flop || len(s.Board) != 3 || !h.IsNextToPlay(p2.Id) {
+		t.Errorf("expected the flop to be dealt with the big blind to act first but got %+v", s)
+	}
+}
+
+func TestFastHandWinsTheChipsOfAHand(t *testing.T) {
+	variants := []Rules{
+		{Blinds: []int{smallBlind, bigBlind}, PreflopBetting: true},
+		{Blinds: []int{smallBlind, bigBlind}},
+		{Blinds: []int{smallBlind, bigBlind}, PreflopBetting: true, Limit: PotLimit, Ante: 1, Rake: Rake{Percent: 5, Cap: 3}},
+		{Blinds: []int{smallBlind, bigBlind}, PreflopBetting: true, Limit: FixedLimit, Raises: RaiseIncrement},
+		{Blinds: []int{smallBlind, bigBlind}, PreflopBetting: true, Straddle: MandatoryStraddle, OddChip: OddChipToHouse},
+		{PreflopBetting: true},
+		{Blinds: []int{smallBlind, bigBlind}, PreflopBetting: true, Ante: 20},
+	}
+	r := rand.New(rand.NewSource(1))
+	for v, rules := range variants {
+		for i := 0; i < 300; i++ {
+			n := 2 + r.Intn(5)
+			if rules.Straddle == MandatoryStraddle && n < 3 {
+				n = 3
+			}
+			stacks := make([]int, n)
+			for j := range stacks {
+				stacks[j] = 10 + r.Intn(200)
+			}
+			seed := r.Int63()
+
+			want := playHandOfBots(t, rules, stacks, seed, randomBots(n, seed))
+			f, err := NewFastHand(rules, n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.Seed(seed)
+			got, err := f.Play(stacks, randomBots(n, seed))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("expected variant %d hand %d to win %v but got %v", v, i, want, got)
+			}
+		}
+	}
+}
+
+func TestFastHandOffersTheMovesOfAHand(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		rules := randomRules(r)
+		n := 2 + r.Intn(5)
+		if err := rules.Validate(n); err != nil {
+			continue
+		}
+		// short and uneven stacks, so that players are often all-in for different stakes
+		stacks := make([]int, n)
+		for j := range stacks {
+			stacks[j] = 10 + r.Intn(40)
+		}
+		seed := r.Int63()
+
+		var want, got []decision
+		won := playHandOfBots(t, rules, stacks, seed, recordingBots(randomBots(n, seed), &want))
+		f, err := NewFastHand(rules, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Seed(seed)
+		net, err := f.Play(stacks, recordingBots(randomBots(n, seed), &got))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("expected hand %d with rules %+v to offer\n%v\nbut got\n%v", i, rules, want, got)
+		}
+		if !reflect.DeepEqual(net, won) {
+			t.Fatalf("expected hand %d with rules %+v to win %v but got %v", i, rules, won, net)
+		}
+	}
+}
+
+// randomRules returns rules combining the limits, raise units, blinds, straddles, antes, rake and
+// odd chip rules of hold'em at random. The rules may be invalid for some numbers of players.
+func randomRules(r *rand.Rand) Rules {
+	rules := Rules{
+		Limit:          BettingLimit(r.Intn(3)),
+		Raises:         RaiseUnit(r.Intn(2)),
+		PreflopBetting: r.Intn(2) == 0,
+		Ante:           r.Intn(3),
+		OddChip:        OddChipRule(r.Intn(2)),
+		Rake:           Rake{Percent: 5 * r.Intn(2), Cap: r.Intn(4)},
+	}
+	switch r.Intn(3) {
+	case 0:
+		if rules.Limit == FixedLimit {
+			rules.Blinds = []int{bigBlind}
+		}
+	case 1:
+		rules.Blinds = []int{bigBlind}
+	case 2:
+		rules.Blinds = []int{smallBlind, bigBlind}
+		rules.Straddle = StraddlePolicy(r.Intn(2))
+	}
+	return rules
+}
+
+// decision is a decision asked of a strategy, other than to post a blind.
+type decision struct {
+	Player string
+	Street Street
+	Pot    int
+	Moves  []Move
+}
+
+// recordingBots returns the strategies, recording every decision asked of them.
+func recordingBots(bots []Strategy, decisions *[]decision) []Strategy {
+	recording := make([]Strategy, len(bots))
+	for i, s := range bots {
+		s := s
+		recording[i] = decideFunc(func(v View, moves []Move) Input {
+			if _, ok := findMove(moves, Blind); !ok {
+				*decisions = append(*decisions, decision{v.Self.Name, v.Street, v.Pot, append([]Move{}, moves...)})
+			}
+			return s.Decide(v, moves)
+		})
+	}
+	return recording
+}
+
+func TestFastHandFoldsInvalidDecisions(t *testing.T) {
+	f, err := NewFastHand(Rules{Blinds: []int{smallBlind, bigBlind}, PreflopBetting: true}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raiser := decideFunc(func(View, []Move) Input { return Input{Action: Raise, Chips: 1} })
+
+	got, err := f.Play([]int{100, 100}, []Strategy{raiser, CallingStation{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0] != -smallBlind || got[1] != smallBlind || f.Invalid(0) != 1 {
+		t.Errorf("expected the small blind to fold the raise but got %v with %d invalid", got, f.Invalid(0))
+	}
+}
+
+func TestFastHandRejectsOmaha(t *testing.T) {
+	if _, err := NewFastHand(Rules{Variant: Omaha, Blinds: []int{smallBlind, bigBlind}}, 2); !errors.Is(err, ErrInvalidRules) {
+		t.Errorf("expected %v but got %v", ErrInvalidRules, err)
+	}
+}
+
+func TestEvaluateCodesMatchesEveryCombination(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 20000; i++ {
+		codes := randomCodes(r, 5+i%4)
+		cs := DecodeCards(codes)
+		want := evaluateCombinations(cs)
+		if got := EvaluateCodes(codes); got != want {
+			t.Fatalf("expected %v to rank %x but got %x", cs, want, got)
+		}
+		if got := Evaluate(cs); got != want {
+			t.Fatalf("expected %v to evaluate to %x but got %x", cs, want, got)
+		}
+	}
+}
+
+func TestEvaluateCodesRanksHands(t *testing.T) {
+	tests := []struct {
+		cards []Card
+		want  HandCategory
+	}{
+		{cards("As", "Ks", "Qs", "Js", "Ts", "2d", "2c"), StraightFlush},
+		{cards("9h", "9d", "9s", "9c", "Ah", "Ad", "As"), FourOfAKind},
+		{cards("Kh", "Kd", "Ks", "Qc", "Qh", "Qd", "2s"), FullHouse},
+		{cards("2h", "7h", "9h", "Jh", "Kh", "Ah", "Ad"), Flush},
+		{cards("Ah", "2d", "3s", "4c", "5h", "Kd", "Qs"), Straight},
+		{cards("7h", "7d", "7s", "2c", "9h", "Jd"), ThreeOfAKind},
+		{cards("7h", "7d", "5s", "5c", "3h", "3d", "Ks"), TwoPair},
+		{cards("Ah", "Ad", "2s", "5c", "9h"), Pair},
+		{cards("Ah", "Jd", "2s", "5c", "9h"), HighCard},
+	}
+	for _, tt := range tests {
+		codes, err := EncodeCards(tt.cards)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := EvaluateCodes(codes).Category(); got != tt.want {
+			t.Errorf("expected %v to be %v but got %v", tt.cards, tt.want, got)
+		}
+	}
+}
+
+func TestCardCodesConvertEveryCard(t *testing.T) {
+	for i, c := range FullDeck() {
+		code, ok := c.Code()
+		if !ok || int(code) != i || code.Card() != c {
+			t.Errorf("expected %v to have code %d but got %d", c, i, code)
+		}
+		if code.Rank() != c.Rank || code.Suit() != c.Suit {
+			t.Errorf("expected code %d to be %v but got %v of %v", code, c, code.Rank(), code.Suit())
+		}
+	}
+	if _, err := EncodeCards([]Card{{}}); !errors.Is(err, ErrUnknownCard) {
+		t.Errorf("expected %v but got %v", ErrUnknownCard, err)
+	}
+}
+
+func TestParseCardsReadsShortNotation(t *testing.T) {
+	want := []Card{{Suit: Spades, Rank: Ace}, {Suit: Diamonds, Rank: King}, {Suit: Clubs, Rank: Seven}}
+	for _, s := range []string{"AsKd7c", "As Kd 7c", "as,kD,7C", "A♠K♦7♣"} {
+		if got, err := ParseCards(s); err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %q to be %v but got %v, %v", s, want, got, err)
+		}
+	}
+	if got, err := ParseCard("10h"); err != nil || got != (Card{Suit: Hearts, Rank: Ten}) {
+		t.Errorf("expected ten of hearts but got %v, %v", got, err)
+	}
+	for _, s := range []string{"", "A", "1s", "Ax", "AsK", "Ass"} {
+		if c, err := ParseCard(s); !errors.Is(err, ErrUnknownCard) {
+			t.Errorf("expected %q to be %v but got %v, %v", s, ErrUnknownCard, c, err)
+		}
+	}
+}
+
+func TestCardsAreWrittenInShortNotation(t *testing.T) {
+	c := Card{Suit: Hearts, Rank: Ten}
+	if c.String() != "Th" || c.Unicode() != "T♥" || fmt.Sprint(cards("As", "2c")) != "[As 2c]" {
+		t.Errorf("expected short notation but got %v, %v and %v", c, c.Unicode(), cards("As", "2c"))
+	}
+	if (Card{}).String() != "??" || (Card{}).Valid() {
+		t.Errorf("expected unknown card but got %v", Card{})
+	}
+	if c.Rank.Name() != "10" || c.Suit.Name() != "Hearts" {
+		t.Errorf("expected long names but got %q of %q", c.Rank.Name(), c.Suit.Name())
+	}
+}
+
+func TestCardJSONAcceptsLongAndShortForms(t *testing.T) {
+	b, err := json.Marshal([]Card{{Suit: Spades, Rank: Ace}, {}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `[{"Suit":"Spades","Rank":"Ace"},{"Suit":"","Rank":""}]`; string(b) != want {
+		t.Errorf("expected %s but got %s", want, b)
+	}
+
+	var got []Card
+	if err := json.Unmarshal([]byte(`[{"Suit":"Spades","Rank":"Ace"},{"Suit":"d","Rank":"T"},"7c",{"Suit":"","Rank":""}]`), &got); err != nil {
+		t.Fatal(err)
+	}
+	if want := append(cards("As", "Td", "7c"), Card{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v but got %v", want, got)
+	}
+	for _, s := range []string{`"Xx"`, `{"Suit":"Spades","Rank":"One"}`} {
+		var c Card
+		if err := json.Unmarshal([]byte(s), &c); !errors.Is(err, ErrUnknownCard) {
+			t.Errorf("expected %s to be %v but got %v", s, ErrUnknownCard, err)
+		}
+	}
+}
+
+// randomCodes returns n distinct cards chosen by the source.
+func randomCodes(r *rand.Rand, n int) []CardCode {
+	codes := make([]CardCode, n)
+	for i, v := range r.Perm(52)[:n] {
+		codes[i] = CardCode(v)
+	}
+	return codes
+}
+
+// decideFunc makes a function a strategy.
+type decideFunc func(View, []Move) Input
+
+func (d decideFunc) Decide(v View, moves []Move) Input { return d(v, moves) }
+
+func randomBots(n int, seed int64) []Strategy {
+	bots := make([]Strategy, n)
+	for i := range bots {
+		bots[i] = NewRandomStrategy(rand.New(rand.NewSource(seed + int64(i))))
+	}
+	return bots
+}
+
+// playHandOfBots plays a hand between the strategies with the given stacks from the dealer and
+// returns the chips each player won. The players are named by their position from the dealer.
+func playHandOfBots(t testing.TB, rules Rules, stacks []int, seed int64, strategies []Strategy) []int {
+	ps := make([]*Player, len(stacks))
+	for i, v := range stacks {
+		ps[i] = NewPlayer(fmt.Sprint(i), v)
+	}
+	rules.Rand = rand.New(rand.NewSource(seed))
+	h, err := NewHandWithRules(ps, ps[0], rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fin, err := h.Begin(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	bots := make(map[string]Strategy)
+	for i, s := range strategies {
+		bots[ps[i].Id] = s
+	}
+	if err := h.PlayBots(bots); err != nil {
+		t.Fatal(err)
+	}
+	<-fin
+	won := make([]int, len(ps))
+	for i, p := range ps {
+		won[i] = p.Chips - stacks[i]
+	}
+	return won
+}
+
+var benchmarkRules = Rules{Blinds: []int{smallBlind, bigBlind}, PreflopBetting: true}
+
+func BenchmarkHandBetweenBots(b *testing.B) {
+	bots := []Strategy{CallingStation{}, NewRandomStrategy(rand.New(rand.NewSource(1)))}
+	rules := benchmarkRules
+	rules.Rand = rand.New(rand.NewSource(1))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ps := []*Player{NewPlayer("a", 200), NewPlayer("b", 200)}
+		h, err := NewHandWithRules(ps, ps[0], rules)
+		if err != nil {
+			b.Fatal(err)
+		}
+		fin, _ := h.Begin(context.Background())
+		if err := h.PlayBots(map[string]Strategy{ps[0].Id: bots[0], ps[1].Id: bots[1]}); err != nil {
+			b.Fatal(err)
+		}
+		<-fin
+	}
+}
+
+func BenchmarkFastHandBetweenBots(b *testing.B) {
+	bots := []Strategy{CallingStation{}, NewRandomStrategy(rand.New(rand.NewSource(1)))}
+	stacks := []int{200, 200}
+	f, err := NewFastHand(benchmarkRules, len(stacks))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := f.Play(stacks, bots); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchmarkHands are hands of seven cards for benchmarking evaluators.
+func benchmarkHands() [][]CardCode {
+	r := rand.New(rand.NewSource(1))
+	hands := make([][]CardCode, 1024)
+	for i := range hands {
+		hands[i] = randomCodes(r, 7)
+	}
+	return hands
+}
+
+func BenchmarkEvaluateCombinations(b *testing.B) {
+	var hands [][]Card
+	for _, v := range benchmarkHands() {
+		hands = append(hands, DecodeCards(v))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		evaluateCombinations(hands[i%len(hands)])
+	}
+}
+
+func BenchmarkEvaluate(b *testing.B) {
+	var hands [][]Card
+	for _, v := range benchmarkHands() {
+		hands = append(hands, DecodeCards(v))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Evaluate(hands[i%len(hands)])
+	}
+}
+
+func BenchmarkEvaluateCodes(b *testing.B) {
+	hands := benchmarkHands()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		EvaluateCodes(hands[i%len(hands)])
+	}
+}
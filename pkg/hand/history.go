@@ -0,0 +1,167 @@
+package hand
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Entry records a move played in a hand, or the correction of one.
+type Entry struct {
+	Seq      int
+	PlayerId string
+	Street   Street
+	Input    Input
+	// Automatic is true for moves played on behalf of a player, such as on timing out.
+	Automatic bool
+	// Corrects is the sequence number of the entry undone by this entry, or zero if this entry is
+	// not a correction.
+	Corrects int
+	Note     string
+}
+
+// ErrNothingToUndo is returned when there are not enough moves on the current street to undo, other
+// than blinds.
+var ErrNothingToUndo = errors.New("no moves to undo on this street")
+
+// action is a move in the action log together with the state of the hand before it was played.
+type action struct {
+	entry  Entry
+	before handSnapshot
+}
+
+type handSnapshot struct {
+	players    []*Player
+	chips      map[*Player]int
+	folded     map[*Player]bool
+	contribs   map[string]int
+	dead       map[string]int
+	nextToPlay *Player
+	stage      stage
+	cards      []Card
+	roundBase  map[string]int
+	aggressor  *Player
+	lastRaise  int
+}
+
+// History returns the moves played in the hand in order, including any corrections.
+func (h *Hand) History() []Entry {
+	h.m.RLock()
+	defer h.m.RUnlock()
+
+	return append([]Entry{}, h.history...)
+}
+
+// Undo rolls back the given number of the most recent moves on the current street, as a floor
+// decision to correct a mistake. The chips, pot, betting round and next to play are restored to how
+// they were before the moves, and a correction is recorded in the history for each move undone.
+// Blinds are not undone, so the earliest move which can be undone is the first action taken by
+// choice on the street.
+func (h *Hand) Undo(moves int, reason string) error {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	if h.isFinished() {
+		return ErrHandFinished
+	}
+	if moves <= 0 || moves > h.undoable() {
+		return fmt.Errorf("%w: %d requested", ErrNothingToUndo, moves)
+	}
+	for i := 0; i < moves; i++ {
+		last := h.log[len(h.log)-1]
+		h.log = h.log[:len(h.log)-1]
+		h.restore(last.before)
+		h.history = append(h.history, Entry{
+			Seq:      len(h.history) + 1,
+			PlayerId: last.entry.PlayerId,
+			Street:   last.entry.Street,
+			Input:    last.entry.Input,
+			Corrects: last.entry.Seq,
+			Note:     reason,
+		})
+	}
+	// players who have since sat out are played for again
+	h.playAway()
+	h.record()
+	h.startTurn()
+	return nil
+}
+
+// undoable returns the number of moves in the action log played on the current street since the
+// blinds were posted.
+func (h *Hand) undoable() int {
+	street := h.stage.street()
+	n := 0
+	for i := len(h.log) - 1; i >= 0; i-- {
+		if a := h.log[i]; a.before.stage.street() != street || a.entry.Input.Action == Blind {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// logMove records a successful move in the action log and the history.
+func (h *Hand) logMove(p *Player, inp Input, before handSnapshot) {
+	e := Entry{
+		Seq:       len(h.history) + 1,
+		PlayerId:  p.Id,
+		Street:    before.stage.street(),
+		Input:     inp,
+		Automatic: h.automatic,
+	}
+	h.history = append(h.history, e)
+	h.log = append(h.log, action{e, before})
+}
+
+func (h *Hand) snapshot() handSnapshot {
+	s := handSnapshot{
+		players:    append([]*Player{}, h.players...),
+		chips:      make(map[*Player]int, len(h.dealt)),
+		folded:     make(map[*Player]bool, len(h.dealt)),
+		contribs:   make(map[string]int, len(h.pot.contribs)),
+		dead:       make(map[string]int, len(h.pot.dead)),
+		nextToPlay: h.nextToPlay,
+		stage:      h.stage.clone(),
+		cards:      append([]Card{}, h.Cards...),
+		roundBase:  h.roundBase,
+		aggressor:  h.aggressor,
+		lastRaise:  h.lastRaise,
+	}
+	for _, v := range h.dealt {
+		s.chips[v] = v.Chips
+		s.folded[v] = v.Folded
+	}
+	for id, v := range h.pot.contribs {
+		s.contribs[id] = v
+	}
+	for id, v := range h.pot.dead {
+		s.dead[id] = v
+	}
+	return s
+}
+
+func (h *Hand) restore(s handSnapshot) {
+	h.players = s.players
+	for p, v := range s.chips {
+		p.Chips = v
+		p.Folded = s.folded[p]
+	}
+	for id := range h.pot.contribs {
+		delete(h.pot.contribs, id)
+	}
+	for id, v := range s.contribs {
+		h.pot.contribs[id] = v
+	}
+	for id := range h.pot.dead {
+		delete(h.pot.dead, id)
+	}
+	for id, v := range s.dead {
+		h.pot.dead[id] = v
+	}
+	h.nextToPlay = s.nextToPlay
+	h.stage = s.stage
+	h.Cards = s.cards
+	h.roundBase = s.roundBase
+	h.aggressor = s.aggressor
+	h.lastRaise = s.lastRaise
+}
@@ -0,0 +1,96 @@
+package hand
+
+// EntryPolicy determines when a player who joins during a hand is dealt in.
+type EntryPolicy int
+
+const (
+	// EnterNextHand deals the player into the next hand without condition.
+	EnterNextHand EntryPolicy = iota
+	// PostBigBlind deals the player into the next hand on condition that they post a big blind
+	// regardless of their position.
+	PostBigBlind
+	// WaitForButton deals the player in once the button has passed them, when the big blind falls
+	// to them.
+	WaitForButton
+)
+
+// JoinNextHand adds a player to the table with the given chips as their stack. A player joining
+// before the hand has begun is dealt into this hand. Otherwise they wait to be dealt into the next
+// hand according to the entry policy.
+func (h *Hand) JoinNextHand(player *Player, chips int, policy EntryPolicy) error {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	if chips <= 0 {
+		return ErrNoChips
+	}
+	for _, v := range append(h.seated, h.waiting...) {
+		if v.Name == player.Name {
+			return ErrDuplicatePlayer
+		}
+	}
+	player.Chips = chips
+	player.Folded = false
+	if !h.isActive() {
+		h.seated = append(h.seated, player)
+		h.dealt = append(h.dealt, player)
+		h.players = append(h.players, player)
+		if h.limits != nil {
+			h.banks[player.Id] = h.limits.TimeBank
+		}
+		return nil
+	}
+
+	switch policy {
+	case PostBigBlind:
+		player.MissedBlinds += h.rules.bigBlind()
+	case WaitForButton:
+		player.waitingForButton = true
+	}
+	h.waiting = append(h.waiting, player)
+	return nil
+}
+
+// Waiting returns the players who joined after the hand began and are waiting for the next hand.
+func (h *Hand) Waiting() []*Player {
+	h.m.RLock()
+	defer h.m.RUnlock()
+
+	return append([]*Player{}, h.waiting...)
+}
+
+// NextPlayers returns the players seated for the next hand: those seated for this hand, including
+// any who have folded or are sitting out, followed by those waiting.
+func (h *Hand) NextPlayers() []*Player {
+	h.m.RLock()
+	defer h.m.RUnlock()
+
+	return append(append([]*Player{}, h.seated...), h.waiting...)
+}
+
+// postOwedBlinds takes the blinds owed by players dealt into the hand, such as those missed while
+// sitting out. Up to a big blind is posted live and any remainder is dead money in the pot, as far as
+// the player's stack allows. A player whose position requires them to post a blind in this hand
+// posts any remainder only.
+func (h *Hand) postOwedBlinds() {
+	bb := h.rules.bigBlind()
+	for i, v := range h.players {
+		if v.MissedBlinds == 0 {
+			continue
+		}
+		owed := v.MissedBlinds
+		v.MissedBlinds = 0
+		live := bb
+		if i < len(h.blinds) {
+			live = 0
+			owed -= h.blinds[i]
+		}
+		live = min(min(live, owed), v.Chips)
+		if live > 0 {
+			h.pot.add(v, live)
+		}
+		if dead := min(owed-live, v.Chips); dead > 0 {
+			h.pot.addDead(v, dead)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package hand
+
+import "math/bits"
+
+// lookup ranks hands of five to seven cards with a read from one of two tables. A hand holding
+// five or more cards of a suit can make nothing better than a flush, so is ranked by the ranks it
+// holds in that suit. Any other hand is ranked by how many cards it holds of each rank, which are
+// numbered in order to index a table for each number of cards.
+var lookup = newLookupTables()
+
+type lookupTables struct {
+	// flushes ranks the best flush or straight flush made from the ranks in a mask of 13 bits.
+	flushes [1 << 13]HandRank
+	// offsets[r][k][q] is added to the index of a hand holding q cards of rank r with k of its
+	// cards not among the lower ranks.
+	offsets [13][8][8]uint32
+	// counts ranks hands of each number of cards without a flush by their index.
+	counts [8][]HandRank
+	// rankCount and suitCount are added for each card to the counts of cards held of each rank,
+	// packed four bits to a rank, and of each suit, packed a byte to a suit.
+	rankCount [64]uint64
+	suitCount [64]uint32
+}
+
+func newLookupTables() *lookupTables {
+	t := &lookupTables{}
+	for c := CardCode(0); c < 52; c++ {
+		t.rankCount[c] = 1 << (4 * (c.Rank() - Two))
+		t.suitCount[c] = 1 << (8 * (c.Suit() - Clubs))
+	}
+	for m := range t.flushes {
+		if bits.OnesCount16(uint16(m)) >= 5 {
+			t.flushes[m] = flushRank(uint16(m))
+		}
+	}
+
+	// ways[n][k] is the number of ways to hold k cards of n ranks, at most four of each
+	var ways [14][8]uint32
+	ways[0][0] = 1
+	for n := 1; n < len(ways); n++ {
+		for k := range ways[n] {
+			for q := 0; q <= 4 && q <= k; q++ {
+				ways[n][k] += ways[n-1][k-q]
+			}
+		}
+	}
+	for r := range t.offsets {
+		for k := range t.offsets[r] {
+			for q := 1; q <= 4; q++ {
+				t.offsets[r][k][q] = t.offsets[r][k][q-1]
+				if k >= q-1 {
+					t.offsets[r][k][q] += ways[12-r][k-(q-1)]
+				}
+			}
+		}
+	}
+
+	for k := 5; k <= 7; k++ {
+		t.counts[k] = make([]HandRank, ways[13][k])
+		var counts [13]uint8
+		var fill func(r int, left int)
+		fill = func(r int, left int) {
+			if r == len(counts) {
+				if left == 0 {
+					var packed uint64
+					for r, q := range counts {
+						packed |= uint64(q) << (4 * r)
+					}
+					t.counts[k][t.index(packed, k)] = rankCounts(counts)
+				}
+				return
+			}
+			for q := 0; q <= 4 && q <= left; q++ {
+				counts[r] = uint8(q)
+				fill(r+1, left-q)
+			}
+			counts[r] = 0
+		}
+		fill(0, k)
+	}
+	return t
+}
+
+// index returns the index of a hand of k cards holding the packed counts of each rank.
+func (t *lookupTables) index(counts uint64, k int) uint32 {
+	var i uint32
+	for r := range t.offsets {
+		q := counts >> (4 * r) & 7
+		i += t.offsets[r][k&7][q]
+		k -= int(q)
+	}
+	return i
+}
+
+// EvaluateCodes returns the rank of the best five card hand that can be made from the given
+// distinct cards, or 0 if there are fewer than five. Hands of five to seven cards are ranked by
+// lookup tables, without allocating.
+func EvaluateCodes(cs []CardCode) HandRank {
+	switch {
+	case len(cs) < 5:
+		return 0
+	case len(cs) > 7:
+		var best HandRank
+		var seven [7]CardCode
+		Combinations(len(cs), 7, func(idx []int) {
+			for i, v := range idx {
+				seven[i] = cs[v]
+			}
+			if r := EvaluateCodes(seven[:]); r > best {
+				best = r
+			}
+		})
+		return best
+	}
+	var counts uint64
+	var held uint32
+	for _, c := range cs {
+		counts += lookup.rankCount[c&63]
+		held += lookup.suitCount[c&63]
+	}
+	// a byte of five or more carries into its fourth bit
+	if flush := (held + 0x03030303) & 0x08080808; flush != 0 {
+		suit := Clubs + Suit(bits.TrailingZeros32(flush)/8)
+		var ranks uint16
+		for _, c := range cs {
+			if c.Suit() == suit {
+				ranks |= 1 << (c.Rank() - Two)
+			}
+		}
+		return lookup.flushes[ranks]
+	}
+	return lookup.counts[len(cs)][lookup.index(counts, len(cs))]
+}
+
+// flushRank returns the rank of the best flush or straight flush made from the ranks in the mask,
+// which has at least five.
+func flushRank(ranks uint16) HandRank {
+	if high, ok := straightHigh(ranks); ok {
+		return category(StraightFlush) | placed(high, 0)
+	}
+	return category(Flush) | kickers(ranks, 0, 5)
+}
+
+// rankCounts returns the rank of the best hand without a flush holding the given number of cards
+// of each rank.
+func rankCounts(counts [13]uint8) HandRank {
+	var present uint16
+	quad := -1
+	var trips, pairs [3]int
+	nt, np := 0, 0
+	for r := 12; r >= 0; r-- {
+		if counts[r] > 0 {
+			present |= 1 << r
+		}
+		switch counts[r] {
+		case 4:
+			quad = r
+		case 3:
+			trips[nt] = r
+			nt++
+		case 2:
+			pairs[np] = r
+			np++
+		}
+	}
+
+	switch {
+	case quad >= 0:
+		return category(FourOfAKind) | placed(quad, 0) | kickers(present&^(1<<quad), 1, 1)
+	case nt > 0 && nt+np > 1:
+		pair := -1
+		if nt > 1 {
+			pair = trips[1]
+		}
+		if np > 0 && pairs[0] > pair {
+			pair = pairs[0]
+		}
+		return category(FullHouse) | placed(trips[0], 0) | placed(pair, 1)
+	}
+	if high, ok := straightHigh(present); ok {
+		return category(Straight) | placed(high, 0)
+	}
+	switch {
+	case nt > 0:
+		return category(ThreeOfAKind) | placed(trips[0], 0) | kickers(present&^(1<<trips[0]), 1, 2)
+	case np > 1:
+		return category(TwoPair) | placed(pairs[0], 0) | placed(pairs[1], 1) |
+			kickers(present&^(1<<pairs[0]|1<<pairs[1]), 2, 1)
+	case np == 1:
+		return category(Pair) | placed(pairs[0], 0) | kickers(present&^(1<<pairs[0]), 1, 3)
+	default:
+		return category(HighCard) | kickers(present, 0, 5)
+	}
+}
+
+// straightHigh returns the rank of the highest card of the best straight among the ranks in the
+// mask, in which an ace may play low.
+func straightHigh(ranks uint16) (int, bool) {
+	// bit 0 is the ace playing low, and bit r+1 the rank r
+	m := ranks<<1 | ranks>>12&1
+	for high := 13; high >= 4; high-- {
+		if run := uint16(0x1f) << (high - 4); m&run == run {
+			return high - 1, true
+		}
+	}
+	return 0, false
+}
+
+func category(c HandCategory) HandRank {
+	return HandRank(c+1) << 20
+}
+
+// placed returns the rank in the given place among the ranks which break ties.
+func placed(rank int, place int) HandRank {
+	return HandRank(rank+1) << (16 - 4*place)
+}
+
+// kickers returns the highest n ranks in the mask, placed from the given place.
+func kickers(ranks uint16, place int, n int) HandRank {
+	var r HandRank
+	for rank := 12; rank >= 0 && n > 0; rank-- {
+		if ranks&(1<<rank) != 0 {
+			r |= placed(rank, place)
+			place++
+			n--
+		}
+	}
+	return r
+}
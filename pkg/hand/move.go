@@ -20,4 +20,8 @@ func NewMinumumBet(min int) RequiredBet {
 	return RequiredBet{Minimum: min, Maximum: math.MaxInt32}
 }
 
+func NewBetRange(min int, max int) RequiredBet {
+	return RequiredBet{Minimum: min, Maximum: max}
+}
+
 func NewMove(a Action, b RequiredBet) Move { return Move{a, b} }
@@ -10,6 +10,14 @@ type Player struct {
 	Chips  int
 	Cards  []Card
 	Folded bool
+	// SittingOut players keep their seat and chips but are dealt out of hands.
+	SittingOut bool
+	// MissedBlinds is the total of the blinds that fell to the player while sitting out.
+	MissedBlinds int
+	// HandsSatOut is the number of consecutive hands the player has been dealt out of.
+	HandsSatOut int
+
+	waitingForButton bool
 }
 
 func NewPlayer(name string, chips int) *Player {
@@ -25,6 +33,13 @@ func (p *Player) String() string {
 	return p.Id
 }
 
+// clone returns a copy of the player which shares nothing with them.
+func (p *Player) clone() *Player {
+	c := *p
+	c.Cards = append([]Card(nil), p.Cards...)
+	return &c
+}
+
 func (p *Player) bet(amount int) {
 	p.Chips = p.Chips - amount
 }
@@ -2,11 +2,13 @@ package hand
 
 type pot struct {
 	contribs map[string]int
+	dead     map[string]int
 }
 
 func newPot() pot {
 	return pot{
 		contribs: make(map[string]int),
+		dead:     make(map[string]int),
 	}
 }
 
@@ -15,8 +17,17 @@ func (p pot) add(pl *Player, amount int) {
 	p.contribs[pl.Id] += amount
 }
 
+// addDead adds chips which do not count towards the player's stake, such as missed small blinds.
+func (p pot) addDead(pl *Player, amount int) {
+	pl.bet(amount)
+	p.dead[pl.Id] += amount
+}
+
 func (p pot) total() int {
 	total := 0
+	for _, v := range p.dead {
+		total += v
+	}
 	for _, v := range p.contribs {
 		total += v
 	}
@@ -33,14 +44,17 @@ func (p pot) maxStake() int {
 	return max
 }
 
-func (p pot) outstandingStake() bool {
-	var anyStake int
-	for _, v := range p.contribs {
-		anyStake = v
-		break
+// outstandingStake reports whether any of the active players who are not all-in has staked less
+// than another active player.
+func (p pot) outstandingStake(active []*Player) bool {
+	max := 0
+	for _, v := range active {
+		if c := p.contribs[v.Id]; c > max {
+			max = c
+		}
 	}
-	for _, v := range p.contribs {
-		if anyStake != v {
+	for _, v := range active {
+		if !p.isAllIn(v) && p.contribs[v.Id] < max {
 			return true
 		}
 	}
@@ -52,3 +66,138 @@ func (p pot) required(pl Player) int {
 	max := p.maxStake()
 	return max - curr
 }
+
+// isAllIn reports whether the player has committed all of their chips to the pot, including any
+// dead money such as an ante.
+func (p pot) isAllIn(pl *Player) bool {
+	return allIn(pl.Chips, p.contribs[pl.Id]+p.dead[pl.Id])
+}
+
+// pots divides the pot between the active players into a main pot and side pots, one for each
+// distinct stake at which an active player is all-in. Dead money and the contributions of players
+// who have folded go to the pots up to the stakes they reached.
+func (p pot) pots(active []*Player) []Pot {
+	var contribs []int
+	var folded, allIns []bool
+	isActive := make(map[string]bool)
+	for _, v := range active {
+		isActive[v.Id] = true
+		contribs = append(contribs, p.contribs[v.Id])
+		folded = append(folded, false)
+		allIns = append(allIns, p.isAllIn(v))
+	}
+	for id, v := range p.contribs {
+		if !isActive[id] {
+			contribs = append(contribs, v)
+			folded = append(folded, true)
+			allIns = append(allIns, false)
+		}
+	}
+	stakes, amounts := sidePots(contribs, folded, allIns, nil, nil)
+
+	ps := make([]Pot, len(stakes))
+	for i, stake := range stakes {
+		ps[i].Amount = amounts[i]
+		for j, v := range active {
+			if eligible(contribs[j], allIns[j], stake) {
+				ps[i].Eligible = append(ps[i].Eligible, v.Id)
+			}
+		}
+	}
+	for _, v := range p.dead {
+		ps[0].Amount += v
+	}
+	return ps
+}
+
+// allIn reports whether a player with the given stack, having put the given chips into the pot, is
+// all-in.
+func allIn(chips int, staked int) bool {
+	return chips <= 0 && staked > 0
+}
+
+// sidePots divides the contributions to the pot into a main pot and side pots, one for each
+// distinct stake at which a player who has not folded is all-in. The stakes of the pots are
+// appended to stakes in ascending order, and the chips contributed up to each stake above the one
+// before to amounts. The highest stake of the players who have not folded is always a stake.
+func sidePots(contribs []int, folded, allIns []bool, stakes, amounts []int) ([]int, []int) {
+	max := 0
+	for i, c := range contribs {
+		if folded[i] {
+			continue
+		}
+		if c > max {
+			max = c
+		}
+		if allIns[i] {
+			stakes = addStake(stakes, c)
+		}
+	}
+	stakes = addStake(stakes, max)
+
+	prev := 0
+	for _, stake := range stakes {
+		amount := 0
+		for _, c := range contribs {
+			amount += min(c, stake) - min(c, prev)
+		}
+		amounts = append(amounts, amount)
+		prev = stake
+	}
+	return stakes, amounts
+}
+
+// addStake inserts the stake in order if it is not already one of the stakes.
+func addStake(stakes []int, stake int) []int {
+	i := 0
+	for i < len(stakes) && stakes[i] < stake {
+		i++
+	}
+	if i < len(stakes) && stakes[i] == stake {
+		return stakes
+	}
+	stakes = append(stakes, 0)
+	copy(stakes[i+1:], stakes[i:])
+	stakes[i] = stake
+	return stakes
+}
+
+// eligible reports whether a player who has not folded may win a pot with the given stake.
+func eligible(contrib int, allIn bool, stake int) bool {
+	return contrib >= stake || !allIn
+}
+
+// takeRake takes the rake from the pots with the given amounts, starting with the main pot.
+func takeRake(rake int, amounts []int) {
+	for i := range amounts {
+		taken := min(rake, amounts[i])
+		amounts[i] -= taken
+		rake -= taken
+	}
+}
+
+// splitShare returns the chips won by the winner of a pot in the given place, in order from the
+// dealer, when the chips are split between the given number of winners.
+func splitShare(chips int, winners int, place int, rule OddChipRule) int {
+	share := chips / winners
+	if rule == OddChipFromDealer && place < chips%winners {
+		share++
+	}
+	return share
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// refund returns every contribution to the pot to the player who made it.
+func (p pot) refund(ps []*Player) {
+	for _, v := range ps {
+		v.Chips += p.contribs[v.Id] + p.dead[v.Id]
+		delete(p.contribs, v.Id)
+		delete(p.dead, v.Id)
+	}
+}
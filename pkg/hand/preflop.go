@@ -1,7 +1,5 @@
 package hand
 
-import "errors"
-
 type preflop struct {
 	blinds map[*Player]blind
 }
@@ -19,8 +17,7 @@ func newPreflop(remaining []*Player, blinds []int) (preflop, error) {
 }
 
 func (curr preflop) requiredBet(h *Hand, p *Player) int {
-	blind := curr.blinds[p]
-	return blind.required
+	return curr.blinds[p].capped(p.Chips).required
 }
 
 func (curr preflop) enter(h *Hand) error {
@@ -32,33 +29,49 @@ func (curr preflop) exit(h *Hand) error {
 	if err != nil {
 		return err
 	}
-	h.nextToPlay = next
+	h.playFrom(next)
 	return nil
 }
 
 func (curr preflop) handleInput(h *Hand, p *Player, inp Input) (stage, error) {
 	switch inp.Action {
 	case Blind:
-		blinds := curr.blinds
-		blind := blinds[p]
+		// a player short of the blind posts what they have
+		blind := curr.blinds[p].capped(p.Chips)
 		b, err := blind.play(inp.Chips)
 		if err != nil {
-			return nil, err
+			return nil, &BetError{PlayerId: p.Id, Action: Blind, Chips: inp.Chips, Allowed: NewExactBet(blind.required)}
 		}
 		h.pot.add(p, blind.required)
-		blinds[p] = *b
-		for _, v := range blinds {
-			if v.required != 0 && !v.played() {
-				return curr, nil
-			}
+		curr.blinds[p] = *b
+		if !curr.posted() {
+			return curr, nil
 		}
 		curr.exit(h)
-		return newFlopState(h.activePlayers()), nil
+		return curr.afterBlinds(h), nil
 	default:
-		return nil, errors.New("unsupported action in preflop")
+		return nil, &ActionError{PlayerId: p.Id, Action: inp.Action, Street: Preflop, Reason: "blinds must be played"}
 	}
 }
 
+// posted reports whether every blind has been posted, counting those of players who are all-in.
+func (curr preflop) posted() bool {
+	for p, v := range curr.blinds {
+		if !v.capped(p.Chips).played() {
+			return false
+		}
+	}
+	return true
+}
+
+// afterBlinds returns the stage following the blinds, in which the players able to act are to bet.
+func (curr preflop) afterBlinds(h *Hand) stage {
+	if h.rules.PreflopBetting {
+		return newPreflopBettingState(h.actors())
+	}
+	return newFlopState(h.actors())
+}
+
 func (curr preflop) validMoves(h *Hand) map[string][]Move {
 	mvs := make(map[string][]Move)
 	plyr := h.nextToPlay
@@ -66,3 +79,15 @@ func (curr preflop) validMoves(h *Hand) map[string][]Move {
 	mvs[plyr.Id] = []Move{NewMove(Blind, NewExactBet(req))}
 	return mvs
 }
+
+func (curr preflop) street() Street {
+	return Preflop
+}
+
+func (curr preflop) clone() stage {
+	bs := make(map[*Player]blind, len(curr.blinds))
+	for k, v := range curr.blinds {
+		bs[k] = v
+	}
+	return preflop{blinds: bs}
+}
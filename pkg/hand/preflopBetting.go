@@ -0,0 +1,22 @@
+package hand
+
+// preflopBetting is the round of betting after the blinds and before the flop, played when the
+// rules ask for it.
+type preflopBetting struct {
+	bettingStage
+}
+
+func newPreflopBettingState(remaining []*Player) preflopBetting {
+	curr := func(bs bettingStage) stage {
+		return preflopBetting{bs}
+	}
+	next := func(remaining []*Player) stage {
+		return newFlopState(remaining)
+	}
+	bs := newBettingStage(remaining, 0, curr, next)
+	return preflopBetting{bs}
+}
+
+func (curr preflopBetting) street() Street {
+	return Preflop
+}
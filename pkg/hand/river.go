@@ -14,3 +14,7 @@ func newRiverState(remaining []*Player) river {
 	bs := newBettingStage(remaining, 5, curr, next)
 	return river{bs}
 }
+
+func (curr river) street() Street {
+	return River
+}
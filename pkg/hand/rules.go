@@ -0,0 +1,178 @@
+package hand
+
+import "math/rand"
+
+// Variant is the poker game played in a hand.
+type Variant int
+
+const (
+	TexasHoldem Variant = iota
+	Omaha
+)
+
+// BettingLimit restricts the size of bets and raises.
+type BettingLimit int
+
+const (
+	NoLimit BettingLimit = iota
+	PotLimit
+	FixedLimit
+)
+
+// StraddlePolicy determines whether a straddle of twice the big blind is posted.
+type StraddlePolicy int
+
+const (
+	NoStraddle StraddlePolicy = iota
+	// MandatoryStraddle requires the player after the big blind to straddle.
+	MandatoryStraddle
+)
+
+// OddChipRule determines who receives the odd chip when a pot cannot be split evenly.
+type OddChipRule int
+
+const (
+	// OddChipFromDealer awards the odd chip to the first winner in order from the dealer.
+	OddChipFromDealer OddChipRule = iota
+	// OddChipToHouse leaves the odd chip out of the pot, adding it to the rake.
+	OddChipToHouse
+)
+
+// RaiseUnit is the unit of the chips played with a raise.
+type RaiseUnit int
+
+const (
+	// RaiseTo raises are the total the player has committed on the street once they have raised.
+	RaiseTo RaiseUnit = iota
+	// RaiseIncrement raises are the chips added to the pot by the raise, including those needed to
+	// call.
+	RaiseIncrement
+)
+
+// Rake is the share of each pot taken by the house.
+type Rake struct {
+	// Percent of the pot taken, from 0 to 100.
+	Percent int
+	// Cap limits the rake taken from a pot when positive.
+	Cap int
+}
+
+// Rules configure how a hand is played. The zero value is a no limit Texas Hold'em hand with no
+// blinds, antes or rake, in which the cards are not dealt by the hand.
+type Rules struct {
+	Variant Variant
+	Limit   BettingLimit
+	// Raises determines the unit of the chips played with a raise and reported in valid moves.
+	Raises RaiseUnit
+	// Blinds are assigned to players in order from the dealer.
+	Blinds []int
+	// PreflopBetting adds a round of betting before the flop is dealt, as in a standard game of
+	// hold'em. Otherwise the first round of betting follows the flop.
+	PreflopBetting bool
+	// Ante is taken from every player dealt in as dead money when the hand begins.
+	Ante       int
+	Straddle   StraddlePolicy
+	Rake       Rake
+	TimeLimits *TimeLimits
+	OddChip    OddChipRule
+	// MaxRuns is the most times the rest of the board may be dealt when the players are all-in
+	// before the river, if they all agree. The board is dealt once if it is 0 or 1.
+	MaxRuns int
+	// Rand shuffles the deck from which the hand deals cards. When nil, cards are not dealt by the
+	// hand so must be assigned to players by the caller.
+	Rand *rand.Rand
+}
+
+// Validate returns an error describing the first rule which is invalid for a hand between the given
+// number of players.
+func (r Rules) Validate(players int) error {
+	if r.Variant < TexasHoldem || r.Variant > Omaha {
+		return invalidRules("unsupported variant %d", r.Variant)
+	}
+	if r.Limit < NoLimit || r.Limit > FixedLimit {
+		return invalidRules("unsupported betting limit %d", r.Limit)
+	}
+	for i, v := range r.Blinds {
+		if v <= 0 {
+			return invalidRules("blind %d of %d must be positive", i+1, v)
+		}
+	}
+	blinds := len(r.Blinds)
+	if r.Straddle == MandatoryStraddle {
+		blinds++
+	}
+	if blinds > players {
+		return invalidRules("%d blinds cannot be assigned to %d players", blinds, players)
+	}
+	if r.Raises < RaiseTo || r.Raises > RaiseIncrement {
+		return invalidRules("unsupported raise unit %d", r.Raises)
+	}
+	if r.Limit == FixedLimit && len(r.Blinds) == 0 {
+		return invalidRules("fixed limit requires blinds to set the size of bets")
+	}
+	if r.Ante < 0 {
+		return invalidRules("ante of %d must not be negative", r.Ante)
+	}
+	if r.Straddle < NoStraddle || r.Straddle > MandatoryStraddle {
+		return invalidRules("unsupported straddle policy %d", r.Straddle)
+	}
+	if r.Straddle == MandatoryStraddle && len(r.Blinds) < 2 {
+		return invalidRules("straddle requires a small and big blind")
+	}
+	if r.Rake.Percent < 0 || r.Rake.Percent > 100 {
+		return invalidRules("rake of %d%% must be between 0 and 100", r.Rake.Percent)
+	}
+	if r.Rake.Cap < 0 {
+		return invalidRules("rake cap of %d must not be negative", r.Rake.Cap)
+	}
+	if r.TimeLimits != nil {
+		if err := r.TimeLimits.validate(); err != nil {
+			return err
+		}
+	}
+	if r.OddChip < OddChipFromDealer || r.OddChip > OddChipToHouse {
+		return invalidRules("unsupported odd chip rule %d", r.OddChip)
+	}
+	if r.MaxRuns < 0 {
+		return invalidRules("maximum of %d runs must not be negative", r.MaxRuns)
+	}
+	return nil
+}
+
+// bigBlind returns the largest of the blinds, or zero if there are none.
+func (r Rules) bigBlind() int {
+	var bb int
+	for _, v := range r.Blinds {
+		if v > bb {
+			bb = v
+		}
+	}
+	return bb
+}
+
+// positionalBlinds returns the blinds posted by players in order from the dealer, including any
+// straddle.
+func (r Rules) positionalBlinds() []int {
+	blinds := append([]int{}, r.Blinds...)
+	if r.Straddle == MandatoryStraddle {
+		blinds = append(blinds, 2*r.bigBlind())
+	}
+	return blinds
+}
+
+// holeCards returns the number of hole cards dealt to each player.
+func (r Rules) holeCards() int {
+	if r.Variant == Omaha {
+		return 4
+	}
+	return 2
+}
+
+// rake returns the amount taken by the house from a pot.
+func (r Rules) rake(pot int) int {
+	rake := pot * r.Rake.Percent / 100
+	if r.Rake.Cap > 0 && rake > r.Rake.Cap {
+		rake = r.Rake.Cap
+	}
+	return rake
+}
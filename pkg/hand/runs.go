@@ -0,0 +1,132 @@
+package hand
+
+import "fmt"
+
+// Run is the result of one deal of the board at showdown.
+type Run struct {
+	Board []Card
+	// Hands are the ranks of the best hands of the players in the showdown.
+	Hands map[string]HandRank
+	// Awards are the chips won by each player from this run of the board.
+	Awards map[string]int
+}
+
+type showdown struct {
+	runs   []Run
+	awards map[string]int
+}
+
+// RunItTimes records the player's agreement to deal the rest of the board the given number of times
+// should the players be all-in before the river. The board is dealt the fewest times agreed by the
+// players in the showdown, and once if any of them has not agreed, with each pot split evenly across
+// the runs. Players may agree at any time before the showdown.
+func (h *Hand) RunItTimes(playerId string, n int) error {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	if h.isFinished() {
+		return ErrHandFinished
+	}
+	if h.player(playerId) == nil {
+		return ErrPlayerNotFound
+	}
+	if n < 1 || (n > 1 && n > h.rules.MaxRuns) {
+		return fmt.Errorf("%w: cannot run the board %d times when the maximum is %d", ErrInvalidAction, n, h.rules.MaxRuns)
+	}
+	if h.runs == nil {
+		h.runs = make(map[string]int)
+	}
+	h.runs[playerId] = n
+	return nil
+}
+
+// boards returns the board of each run at showdown. The first is the board already dealt and the
+// others share the cards which were on the board when betting closed.
+func (h *Hand) boards() [][]Card {
+	boards := [][]Card{h.Cards}
+	remaining := len(h.Cards) - h.settled
+	if h.deck == nil || remaining <= 0 {
+		return boards
+	}
+	n := h.rules.MaxRuns
+	for _, v := range h.players {
+		if h.runs[v.Id] < n {
+			n = h.runs[v.Id]
+		}
+	}
+	for i := 1; i < n && len(h.deck.cards) >= remaining; i++ {
+		board := append([]Card{}, h.Cards[:h.settled]...)
+		boards = append(boards, append(board, h.deck.deal(remaining)...))
+	}
+	return boards
+}
+
+// showdown divides the pots between the players with the best hands on each board, after taking
+// any rake from the main pot. Each pot is split evenly between the runs, with odd chips going to
+// the earlier runs, and then between the winners of each run according to the odd chip rule.
+func (h *Hand) showdown(boards [][]Card) *showdown {
+	pots := h.pot.pots(h.players)
+	amounts := make([]int, len(pots))
+	for i, pt := range pots {
+		amounts[i] = pt.Amount
+	}
+	takeRake(h.rules.rake(h.pot.total()), amounts)
+
+	sd := &showdown{awards: make(map[string]int)}
+	for r, board := range boards {
+		run := Run{Board: board, Hands: make(map[string]HandRank), Awards: make(map[string]int)}
+		for _, v := range h.players {
+			run.Hands[v.Id] = h.evaluate(v, board)
+		}
+		for i, pt := range pots {
+			share := amounts[i] / len(boards)
+			if r < amounts[i]%len(boards) {
+				share++
+			}
+			var best HandRank
+			var winners []string
+			for _, id := range pt.Eligible {
+				switch rank := run.Hands[id]; {
+				case rank > best:
+					best, winners = rank, []string{id}
+				case rank == best:
+					winners = append(winners, id)
+				}
+			}
+			for id, v := range h.split(share, winners) {
+				run.Awards[id] += v
+				sd.awards[id] += v
+			}
+		}
+		sd.runs = append(sd.runs, run)
+	}
+	return sd
+}
+
+// split divides the chips between the winners, giving any odd chips to the first winners in order
+// from the dealer or leaving them to the house.
+func (h *Hand) split(chips int, winners []string) map[string]int {
+	awards := make(map[string]int)
+	if len(winners) == 0 {
+		return awards
+	}
+	place := 0
+	for _, v := range h.dealt {
+		for _, id := range winners {
+			if v.Id != id {
+				continue
+			}
+			awards[id] = splitShare(chips, len(winners), place, h.rules.OddChip)
+			place++
+		}
+	}
+	return awards
+}
+
+// evaluate returns the rank of the player's best hand with the board.
+func (h *Hand) evaluate(p *Player, board []Card) HandRank {
+	if h.rules.Variant == Omaha {
+		return EvaluateOmaha(p.Cards, board)
+	}
+	return Evaluate(append(append([]Card{}, p.Cards...), board...))
+}
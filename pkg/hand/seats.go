@@ -0,0 +1,137 @@
+package hand
+
+import "fmt"
+
+// MaxSeats is the largest number of seats at a table.
+const MaxSeats = 10
+
+// Seats are the fixed positions around a table, numbered clockwise from 1. A seat is either empty
+// or occupied by a single player, and players keep their seat from hand to hand. Seats are not safe
+// for concurrent use and would typically be changed only between hands.
+type Seats struct {
+	players []*Player
+}
+
+// NewSeats creates a table with the given number of empty seats.
+func NewSeats(n int) (*Seats, error) {
+	if n < 2 || n > MaxSeats {
+		return nil, fmt.Errorf("%w: %d seats", ErrInvalidSeat, n)
+	}
+	return &Seats{players: make([]*Player, n)}, nil
+}
+
+// Len returns the number of seats, whether empty or occupied.
+func (s *Seats) Len() int {
+	return len(s.players)
+}
+
+// At returns the player in the given seat, or nil if the seat is empty or does not exist.
+func (s *Seats) At(seat int) *Player {
+	if seat < 1 || seat > len(s.players) {
+		return nil
+	}
+	return s.players[seat-1]
+}
+
+// SeatOf returns the seat of the player denoted by the given ID, or 0 if they are not seated.
+func (s *Seats) SeatOf(id string) int {
+	for i, p := range s.players {
+		if p != nil && p.Id == id {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// Sit seats the player in the given seat.
+func (s *Seats) Sit(seat int, p *Player) error {
+	if seat < 1 || seat > len(s.players) {
+		return fmt.Errorf("%w: seat %d of %d", ErrInvalidSeat, seat, len(s.players))
+	}
+	if s.players[seat-1] != nil {
+		return fmt.Errorf("%w: seat %d", ErrSeatTaken, seat)
+	}
+	for _, v := range s.players {
+		if v != nil && (v.Id == p.Id || v.Name == p.Name) {
+			return ErrDuplicatePlayer
+		}
+	}
+	s.players[seat-1] = p
+	return nil
+}
+
+// SitAnywhere seats the player in the lowest numbered empty seat and returns it.
+func (s *Seats) SitAnywhere(p *Player) (int, error) {
+	for i, v := range s.players {
+		if v == nil {
+			return i + 1, s.Sit(i+1, p)
+		}
+	}
+	return 0, ErrTableFull
+}
+
+// Leave empties the seat of the player denoted by the given ID and returns the seat they left.
+func (s *Seats) Leave(id string) (int, error) {
+	seat := s.SeatOf(id)
+	if seat == 0 {
+		return 0, ErrPlayerNotFound
+	}
+	s.players[seat-1] = nil
+	return seat, nil
+}
+
+// Occupied returns the numbers of the occupied seats in order.
+func (s *Seats) Occupied() []int {
+	var seats []int
+	for i, v := range s.players {
+		if v != nil {
+			seats = append(seats, i+1)
+		}
+	}
+	return seats
+}
+
+// Next returns the first occupied seat clockwise after the given seat, wrapping around the table,
+// or 0 if every seat is empty.
+func (s *Seats) Next(seat int) int {
+	for i := 1; i <= len(s.players); i++ {
+		next := (seat+i-1)%len(s.players) + 1
+		if s.players[next-1] != nil {
+			return next
+		}
+	}
+	return 0
+}
+
+// NewHandAtSeats creates a new hand with the players seated at the table, with the dealer button
+// in the given seat. The blinds and the order of play are taken clockwise from the button over the
+// occupied seats, and the seat of each player is reported in views of the hand.
+func NewHandAtSeats(s *Seats, button int, rules Rules) (*Hand, error) {
+	dealer := s.At(button)
+	if dealer == nil {
+		return nil, fmt.Errorf("%w: button must be in an occupied seat", ErrInvalidSeat)
+	}
+	var ps []*Player
+	seats := make(map[string]int)
+	for _, seat := range s.Occupied() {
+		p := s.At(seat)
+		ps = append(ps, p)
+		seats[p.Id] = seat
+	}
+	h, err := NewHandWithRules(ps, dealer, rules)
+	if err != nil {
+		return nil, err
+	}
+	h.seats = seats
+	h.button = button
+	return h, nil
+}
+
+// Seat returns the seat of the player denoted by the given ID, or 0 if the hand was not dealt at
+// a table with fixed seats.
+func (h *Hand) Seat(id string) int {
+	h.m.RLock()
+	defer h.m.RUnlock()
+
+	return h.seats[id]
+}
@@ -0,0 +1,134 @@
+package hand
+
+// SitOut marks the player as sitting out. A player who sits out keeps their seat and chips but is
+// dealt out of subsequent hands. If they are still in this hand they fold when it is next their
+// turn, or post their blind if one is due.
+func (h *Hand) SitOut(playerId string) error {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	p := h.player(playerId)
+	if p == nil {
+		return ErrPlayerNotFound
+	}
+	p.SittingOut = true
+	if h.isActive() {
+		h.playAway()
+		h.startTurn()
+	}
+	return nil
+}
+
+// SitIn returns a player who was sitting out so that they are dealt into the next hand. Any blinds
+// they missed while away remain recorded against them.
+func (h *Hand) SitIn(playerId string) error {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	p := h.player(playerId)
+	if p == nil {
+		return ErrPlayerNotFound
+	}
+	p.SittingOut = false
+	p.HandsSatOut = 0
+	return nil
+}
+
+// PruneAway removes the players who have sat out for at least the given number of orbits of the
+// table, where an orbit is one hand per player seated. It returns the players who remain seated and
+// those who were removed.
+func PruneAway(ps []*Player, orbits int) (seated []*Player, removed []*Player) {
+	limit := orbits * len(ps)
+	for _, v := range ps {
+		if v.SittingOut && v.HandsSatOut >= limit {
+			removed = append(removed, v)
+		} else {
+			seated = append(seated, v)
+		}
+	}
+	return seated, removed
+}
+
+// dealIn returns the players ordered from the dealer who are dealt into a hand, without changing
+// them. Players sitting out are dealt out, as are players waiting for the button until the big blind
+// falls to them.
+func dealIn(ps []*Player, blinds []int) []*Player {
+	var in []*Player
+	for i, v := range ps {
+		if dealtIn(v, i, blinds) {
+			in = append(in, v)
+		}
+	}
+	return in
+}
+
+// seatForHand prepares the players ordered from the dealer for a hand they have been dealt into by
+// dealIn. Players sitting out are charged any blind that would have fallen to them.
+func seatForHand(ps []*Player, blinds []int) {
+	for i, v := range ps {
+		switch {
+		case v.SittingOut:
+			v.HandsSatOut++
+			if i < len(blinds) {
+				v.MissedBlinds += blinds[i]
+			}
+		case dealtIn(v, i, blinds):
+			v.waitingForButton = false
+			v.Folded = false
+		}
+	}
+}
+
+// dealtIn reports whether the player in the given position from the dealer is dealt into a hand
+// with the blinds.
+func dealtIn(p *Player, position int, blinds []int) bool {
+	if p.SittingOut {
+		return false
+	}
+	return !p.waitingForButton || len(blinds) == 0 || position == len(blinds)-1
+}
+
+// playAway plays for each player who is next to play but sitting out until a present player is
+// next to play or the hand is won. A player whose move cannot be played is folded, so that the hand
+// is not left waiting on them.
+func (h *Hand) playAway() {
+	for h.nextToPlay != nil && h.nextToPlay.SittingOut {
+		if h.isFinished() {
+			return
+		}
+		p := h.nextToPlay
+		h.automatic = true
+		inp := h.automaticInput(p, Fold, Blind)
+		err := h.play(p, inp)
+		if err != nil && inp.Action != Fold {
+			err = h.play(p, Input{Action: Fold})
+		}
+		h.automatic = false
+		if err != nil {
+			return
+		}
+	}
+}
+
+// automaticInput returns an input for the first of the given actions which is a valid move for the
+// player, or a fold if none are valid.
+func (h *Hand) automaticInput(p *Player, preferred ...Action) Input {
+	mvs := h.stage.validMoves(h)[p.Id]
+	for _, a := range preferred {
+		for _, v := range mvs {
+			if v.Action == a {
+				return Input{Action: a, Chips: v.Bet.Minimum}
+			}
+		}
+	}
+	return Input{Action: Fold}
+}
+
+func (h *Hand) player(id string) *Player {
+	for _, v := range h.players {
+		if v.Id == id {
+			return v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package hand
+
+import "time"
+
+// BroadcastDelay configures the delayed broadcast of a hand, which reveals every player's hole
+// cards as the hand was at least the given number of actions and at least the given duration ago.
+// Both delays must have passed, so either may be left at zero to delay the broadcast by the other
+// alone.
+type BroadcastDelay struct {
+	Actions  int
+	Duration time.Duration
+	// Clock defaults to the system clock when nil.
+	Clock Clock
+}
+
+type broadcast struct {
+	delay     BroadcastDelay
+	snapshots []snapshot
+	// start is the hand as it began as seen by a spectator, which is broadcast until the delay has
+	// passed.
+	start View
+}
+
+type snapshot struct {
+	at   time.Time
+	view View
+}
+
+// SpectatorView returns the hand as seen by someone who is not playing. No hole cards are shown
+// other than those shown at showdown, and every player in the hand is an opponent.
+func (h *Hand) SpectatorView() View {
+	h.m.RLock()
+	defer h.m.RUnlock()
+
+	return h.view(func(p *Player) bool { return h.shown[p.Id] })
+}
+
+// SetBroadcastDelay enables the delayed broadcast of the hand. It must be called before the hand
+// begins.
+func (h *Hand) SetBroadcastDelay(d BroadcastDelay) error {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	if h.isActive() {
+		return ErrHandActive
+	}
+	if d.Actions < 0 || d.Duration < 0 {
+		return invalidRules("broadcast delay must not be negative")
+	}
+	if d.Clock == nil {
+		d.Clock = systemClock{}
+	}
+	h.broadcast = &broadcast{delay: d}
+	return nil
+}
+
+// BroadcastView returns the hand with every player's hole cards revealed, as it was when it last
+// satisfied the broadcast delay. Until the hand has been played for long enough to satisfy it, the
+// hand is returned as it began with no hole cards shown, or an empty view before it begins.
+func (h *Hand) BroadcastView() (View, error) {
+	h.m.RLock()
+	defer h.m.RUnlock()
+
+	if h.broadcast == nil {
+		return View{}, ErrNotBroadcast
+	}
+	b := h.broadcast
+	now := b.delay.Clock.Now()
+	for i := len(b.snapshots) - 1 - b.delay.Actions; i >= 0; i-- {
+		if now.Sub(b.snapshots[i].at) >= b.delay.Duration {
+			return b.snapshots[i].view, nil
+		}
+	}
+	return b.start, nil
+}
+
+// record takes a snapshot of the hand for broadcast, if enabled.
+func (h *Hand) record() {
+	if h.broadcast == nil {
+		return
+	}
+	if len(h.broadcast.snapshots) == 0 {
+		h.broadcast.start = h.view(func(p *Player) bool { return h.shown[p.Id] })
+	}
+	h.broadcast.snapshots = append(h.broadcast.snapshots, snapshot{
+		at:   h.broadcast.delay.Clock.Now(),
+		view: h.view(func(*Player) bool { return true }),
+	})
+}
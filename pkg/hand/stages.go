@@ -8,6 +8,8 @@ type stage interface {
 	validMoves(h *Hand) map[string][]Move
 	requiredBet(h *Hand, p *Player) int
 	exit(h *Hand) error
+	street() Street
+	clone() stage
 }
 
 type Input struct {
@@ -24,4 +26,6 @@ const (
 	Fold
 	Call
 	Raise
+	Bet
+	AllIn
 )
@@ -0,0 +1,107 @@
+//go:generate stringer -type=Street
+
+package hand
+
+// Street is a stage of a hand.
+type Street int
+
+const (
+	Preflop Street = iota
+	Flop
+	Turn
+	River
+	Showdown
+)
+
+// State is a read-only snapshot of a hand.
+type State struct {
+	Street Street
+	Board  []Card
+	// Pot is the total of all chips in the pot including dead money.
+	Pot int
+	// Pots divides the pot into the main pot followed by any side pots.
+	Pots []Pot
+	// CurrentBet is the highest amount committed by a player on this street.
+	CurrentBet int
+	// Committed is the amount committed on this street by each player by ID.
+	Committed map[string]int
+	// LastAggressor is the ID of the last player to raise on this street, if any.
+	LastAggressor string
+	// NextToPlay is the ID of the player next to act, if any.
+	NextToPlay string
+	// TurnOrder is the IDs of the players able to act in turn order, starting from the next to play.
+	// It includes those who have already acted on this street.
+	TurnOrder []string
+}
+
+// Pot is an amount in the pot which may be won by the players eligible for it.
+type Pot struct {
+	Amount   int
+	Eligible []string
+}
+
+// State returns a snapshot of the hand.
+func (h *Hand) State() State {
+	h.m.RLock()
+	defer h.m.RUnlock()
+
+	return h.state()
+}
+
+func (h *Hand) state() State {
+	s := State{
+		Street:    h.stage.street(),
+		Board:     append([]Card{}, h.Cards...),
+		Pot:       h.pot.total(),
+		Pots:      h.pot.pots(h.players),
+		Committed: make(map[string]int),
+	}
+	for id, v := range h.pot.contribs {
+		committed := v - h.roundBase[id]
+		s.Committed[id] = committed
+		if committed > s.CurrentBet {
+			s.CurrentBet = committed
+		}
+	}
+	if h.aggressor != nil {
+		s.LastAggressor = h.aggressor.Id
+	}
+	if h.nextToPlay != nil && s.Street != Showdown {
+		s.NextToPlay = h.nextToPlay.Id
+		s.TurnOrder = h.turnOrder()
+	}
+	return s
+}
+
+// turnOrder returns the IDs of the players who are not all-in in turn order, starting from the next
+// to play, whether or not they have acted on this street.
+func (h *Hand) turnOrder() []string {
+	var idx int
+	for i, v := range h.players {
+		if v == h.nextToPlay {
+			idx = i
+		}
+	}
+	var ids []string
+	for i := range h.players {
+		p := h.players[(idx+i)%len(h.players)]
+		if !h.pot.isAllIn(p) {
+			ids = append(ids, p.Id)
+		}
+	}
+	return ids
+}
+
+// startStreet resets the betting round state when moving between streets. Blinds are carried into
+// the first betting round.
+func (h *Hand) startStreet(prev stage) {
+	h.aggressor = nil
+	if _, ok := prev.(preflop); ok {
+		return
+	}
+	h.lastRaise = 0
+	h.roundBase = make(map[string]int, len(h.pot.contribs))
+	for id, v := range h.pot.contribs {
+		h.roundBase[id] = v
+	}
+}
@@ -0,0 +1,198 @@
+package hand
+
+import (
+	"errors"
+	"math/rand"
+)
+
+// Strategy decides the moves of a computer player.
+type Strategy interface {
+	// Decide returns the input to play given the player's view of the hand and their valid moves.
+	Decide(v View, moves []Move) Input
+}
+
+// PlayStrategy plays the move decided by the strategy for the player denoted by the given ID. An
+// OutOfTurnError is returned without asking the strategy if it is not the player's turn, and
+// ErrStaleDecision if the hand moved on, such as by the player timing out, while it decided.
+func (h *Hand) PlayStrategy(playerId string, s Strategy) error {
+	p, v, seq, err := h.turnView(playerId)
+	if err != nil {
+		return err
+	}
+	inp := s.Decide(v, v.Moves)
+
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	if p != h.nextToPlay {
+		return h.outOfTurn(p)
+	}
+	if len(h.history) != seq {
+		return ErrStaleDecision
+	}
+	return h.handleInput(p, inp)
+}
+
+// turnView returns the player whose turn it is and their view of the hand, with the length of the
+// history when it was taken. An error is returned if it is not the player's turn.
+func (h *Hand) turnView(playerId string) (*Player, View, int, error) {
+	h.m.RLock()
+	defer h.m.RUnlock()
+
+	p := h.player(playerId)
+	if p == nil {
+		return nil, View{}, 0, ErrPlayerNotFound
+	}
+	if p != h.nextToPlay {
+		return nil, View{}, 0, h.outOfTurn(p)
+	}
+	v, err := h.viewFor(playerId)
+	return p, v, len(h.history), err
+}
+
+// PlayBots plays the moves of the players with a strategy for as long as one of them is next to
+// play, returning when it is the turn of a player without one or the hand is finished. An error is
+// returned if a strategy decides on a move which is not valid.
+func (h *Hand) PlayBots(bots map[string]Strategy) error {
+	for {
+		id := h.State().NextToPlay
+		s, ok := bots[id]
+		if id == "" || !ok {
+			return nil
+		}
+		// a decision overtaken by the hand is asked for again on the hand as it now is
+		if err := h.PlayStrategy(id, s); err != nil && !errors.Is(err, ErrStaleDecision) {
+			return err
+		}
+	}
+}
+
+// RandomStrategy plays a valid move chosen at random, betting a random amount when a range of bets
+// is allowed. It is not safe for concurrent use.
+type RandomStrategy struct {
+	rand *rand.Rand
+}
+
+// NewRandomStrategy creates a strategy which chooses moves using the given source.
+func NewRandomStrategy(r *rand.Rand) *RandomStrategy {
+	return &RandomStrategy{r}
+}
+
+func (s *RandomStrategy) Decide(v View, moves []Move) Input {
+	if m, ok := findMove(moves, Blind); ok {
+		return Input{Action: Blind, Chips: m.Bet.Minimum}
+	}
+	if len(moves) == 0 {
+		return Input{Action: Fold}
+	}
+	m := moves[s.rand.Intn(len(moves))]
+	chips := m.Bet.Minimum
+	if m.Bet.Maximum > m.Bet.Minimum {
+		chips += s.rand.Intn(m.Bet.Maximum - m.Bet.Minimum + 1)
+	}
+	return Input{Action: m.Action, Chips: chips}
+}
+
+// CallingStation checks or calls whatever the bet and never folds, bets or raises.
+type CallingStation struct{}
+
+func (CallingStation) Decide(v View, moves []Move) Input {
+	return passive(moves)
+}
+
+// TightAggressive plays strong hands aggressively, betting and raising the minimum, calls with
+// reasonable hands when the price is small, and otherwise checks or folds. The strength of a hand
+// is judged from the hole cards before the flop and from the best hand made afterwards.
+type TightAggressive struct{}
+
+func (TightAggressive) Decide(v View, moves []Move) Input {
+	if m, ok := findMove(moves, Blind); ok {
+		return Input{Action: Blind, Chips: m.Bet.Minimum}
+	}
+	strength := handStrength(v.Self.Cards, v.Board)
+	switch {
+	case strength >= 0.75:
+		for _, a := range []Action{Raise, Bet} {
+			if m, ok := findMove(moves, a); ok {
+				return Input{Action: a, Chips: m.Bet.Minimum}
+			}
+		}
+		return passive(moves)
+	case strength >= 0.45:
+		if m, ok := findMove(moves, Call); ok && m.Bet.Minimum*2 > v.Pot {
+			return Input{Action: Fold}
+		}
+		return passive(moves)
+	}
+	if _, ok := findMove(moves, Check); ok {
+		return Input{Action: Check}
+	}
+	return Input{Action: Fold}
+}
+
+// passive returns the input which posts a blind, checks or calls, in that order of preference.
+func passive(moves []Move) Input {
+	for _, a := range []Action{Blind, Check, Call} {
+		if m, ok := findMove(moves, a); ok {
+			return Input{Action: a, Chips: m.Bet.Minimum}
+		}
+	}
+	if m, ok := findMove(moves, AllIn); ok {
+		return Input{Action: AllIn, Chips: m.Bet.Minimum}
+	}
+	return Input{Action: Fold}
+}
+
+// handStrength estimates the strength of the hole cards with the board from 0 to 1. Hands whose
+// cards are not known are given a middling strength.
+func handStrength(hole []Card, board []Card) float64 {
+	if len(hole) < 2 || rankValue(hole[0]) < 0 || rankValue(hole[1]) < 0 {
+		return 0.5
+	}
+	var known []Card
+	for _, c := range board {
+		if rankValue(c) >= 0 {
+			known = append(known, c)
+		}
+	}
+	if len(known) < 3 {
+		return preflopStrength(hole[0], hole[1])
+	}
+	switch r := Evaluate(append(append([]Card{}, hole...), known...)); {
+	case r.Category() >= TwoPair:
+		return 1
+	case r.Category() == Pair:
+		return 0.6
+	default:
+		return 0.2
+	}
+}
+
+// preflopStrength scores two hole cards by their ranks, favouring pairs, suited and connected
+// cards.
+func preflopStrength(c1, c2 Card) float64 {
+	high, low := rankValue(c1), rankValue(c2)
+	if low > high {
+		high, low = low, high
+	}
+	if high == low {
+		return 0.5 + float64(high)/24
+	}
+	s := float64(high+low) / 24
+	if c1.Suit == c2.Suit {
+		s += 0.1
+	}
+	if high-low == 1 {
+		s += 0.05
+	}
+	return s
+}
+
+func findMove(moves []Move, a Action) (Move, bool) {
+	for _, m := range moves {
+		if m.Action == a {
+			return m, true
+		}
+	}
+	return Move{}, false
+}
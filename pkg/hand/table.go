@@ -0,0 +1,199 @@
+package hand
+
+// Table deals a session of hands to the players at fixed seats. The blinds move by the dead button
+// rule: the big blind advances to the next seat with a player dealt in every hand, each other blind
+// falls to the seat which had the following blind in the previous hand, and the button to the seat
+// which had the first blind. A blind is dead, and not posted, if its seat is empty or the player in
+// it is not dealt in, and the button may likewise be dead.
+//
+// Players sitting out when the big blind passes their seat are charged all the blinds they missed,
+// which they post when they return. Players who have sat down between the button and the big blind
+// are dealt out until the big blind reaches them. A Table is not safe for concurrent use.
+type Table struct {
+	Seats *Seats
+	Rules Rules
+
+	button int
+	blinds []int
+	live   []bool
+	hand   *Hand
+}
+
+// NewTable creates a table with the given seats, whose hands are played according to the rules.
+func NewTable(seats *Seats, rules Rules) *Table {
+	return &Table{Seats: seats, Rules: rules}
+}
+
+// Button returns the seat of the button in the current hand, which may be empty, or 0 before the
+// first hand is dealt.
+func (t *Table) Button() int {
+	return t.button
+}
+
+// Blinds returns the seats of the blinds in the current hand in order of posting, with 0 for those
+// which are dead.
+func (t *Table) Blinds() []int {
+	seats := make([]int, len(t.blinds))
+	for i, v := range t.blinds {
+		if t.live[i] {
+			seats[i] = v
+		}
+	}
+	return seats
+}
+
+// MissedBlinds returns the chips of the blinds owed by the player in the given seat.
+func (t *Table) MissedBlinds(seat int) int {
+	if p := t.Seats.At(seat); p != nil {
+		return p.MissedBlinds
+	}
+	return 0
+}
+
+// NextHand moves the button and blinds on and creates the next hand with the players who are dealt
+// in. ErrHandActive is returned if the previous hand has not finished.
+func (t *Table) NextHand() (*Hand, error) {
+	if t.hand != nil {
+		t.hand.m.RLock()
+		finished := t.hand.isFinished()
+		t.hand.m.RUnlock()
+		if !finished {
+			return nil, ErrHandActive
+		}
+	}
+	active := t.activeSeats()
+	if len(active) <= 1 {
+		return nil, ErrNotEnoughPlayers
+	}
+
+	button, blinds := t.nextPositions(active)
+
+	// players are dealt in from the first live blind, skipping any who have sat down between the
+	// blinds as they would otherwise be assigned one
+	var ps []*Player
+	var amounts []int
+	live := make([]bool, len(blinds))
+	first := 0
+	for i, seat := range blinds {
+		live[i] = active[seat] && (i == len(blinds)-1 || seat != blinds[len(blinds)-1])
+		if live[i] {
+			if first == 0 {
+				first = seat
+			}
+			amounts = append(amounts, t.Rules.Blinds[i])
+		}
+	}
+	if first == 0 {
+		first = t.Seats.Next(button)
+	}
+	inBlinds := len(blinds) > 0
+	seats := make(map[string]int)
+	for i := 0; i < t.Seats.Len(); i++ {
+		seat := (first+i-1)%t.Seats.Len() + 1
+		if !active[seat] {
+			continue
+		}
+		if len(blinds) > 0 && seat == blinds[len(blinds)-1] {
+			inBlinds = false
+		} else if inBlinds && !contains(blinds, seat) {
+			continue
+		}
+		p := t.Seats.At(seat)
+		ps = append(ps, p)
+		seats[p.Id] = seat
+	}
+
+	rules := t.Rules
+	rules.Blinds = amounts
+	h, err := NewHandWithRules(ps, ps[0], rules)
+	if err != nil {
+		return nil, err
+	}
+	t.chargeMissedBlinds(blinds)
+	for _, seat := range t.Seats.Occupied() {
+		if p := t.Seats.At(seat); p.SittingOut {
+			p.HandsSatOut++
+		}
+	}
+	h.seats = seats
+	h.button = button
+	t.button, t.blinds, t.live, t.hand = button, blinds, live, h
+	return h, nil
+}
+
+// activeSeats returns the seats with a player who can be dealt in.
+func (t *Table) activeSeats() map[int]bool {
+	active := make(map[int]bool)
+	for _, seat := range t.Seats.Occupied() {
+		if p := t.Seats.At(seat); !p.SittingOut && p.Chips > 0 {
+			active[seat] = true
+		}
+	}
+	return active
+}
+
+// nextPositions returns the seats of the button and blinds for the next hand, whether or not they
+// are dead.
+func (t *Table) nextPositions(active map[int]bool) (button int, blinds []int) {
+	n := len(t.Rules.Blinds)
+	next := func(seat int) int {
+		for i := 1; i <= t.Seats.Len(); i++ {
+			s := (seat+i-1)%t.Seats.Len() + 1
+			if active[s] {
+				return s
+			}
+		}
+		return 0
+	}
+	blinds = make([]int, n)
+	switch {
+	case t.button == 0 || n == 0:
+		// the first hand, or one without blinds, moves the button to the next player dealt in
+		button = next(t.button)
+		if len(active) == 2 && n == 2 {
+			blinds[0], blinds[1] = button, next(button)
+			return button, blinds
+		}
+		seat := button
+		for i := range blinds {
+			seat = next(seat)
+			blinds[i] = seat
+		}
+		return button, blinds
+	case len(active) == 2 && n == 2:
+		// heads up the button posts the small blind
+		blinds[1] = next(t.blinds[1])
+		blinds[0] = next(blinds[1])
+		return blinds[0], blinds
+	}
+	blinds[n-1] = next(t.blinds[n-1])
+	copy(blinds[:n-1], t.blinds[1:])
+	return t.blinds[0], blinds
+}
+
+// chargeMissedBlinds charges every blind to the players sitting out in the seats passed over by the
+// big blind since the previous hand.
+func (t *Table) chargeMissedBlinds(blinds []int) {
+	if t.button == 0 || len(blinds) == 0 {
+		return
+	}
+	total := 0
+	for _, v := range t.Rules.Blinds {
+		total += v
+	}
+	from, to := t.blinds[len(t.blinds)-1], blinds[len(blinds)-1]
+	for seat := t.Seats.Next(from); seat != to && seat != from && seat != 0; seat = t.Seats.Next(seat) {
+		if p := t.Seats.At(seat); p.SittingOut {
+			p.MissedBlinds += total
+		}
+	}
+}
+
+func contains(seats []int, seat int) bool {
+	for _, v := range seats {
+		if v == seat {
+			return true
+		}
+	}
+	return false
+}
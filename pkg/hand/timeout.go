@@ -0,0 +1,135 @@
+package hand
+
+import "time"
+
+// TimeLimits configures how long players have to act. Each player has PerAction to act on every
+// turn after which their time bank is drawn down. When both are exhausted the player checks if
+// possible, otherwise folds. Blinds cannot be folded so are posted on the player's behalf.
+type TimeLimits struct {
+	PerAction time.Duration
+	TimeBank  time.Duration
+	// Clock defaults to the system clock when nil.
+	Clock Clock
+	// OnTimeout is called, if set, with the move played on behalf of a player who ran out of time.
+	OnTimeout func(Timeout)
+}
+
+// Timeout is a notice that a move was played automatically because a player ran out of time.
+type Timeout struct {
+	HandId   string
+	PlayerId string
+	Input    Input
+}
+
+type turnTimer struct {
+	seq     int
+	started time.Time
+	t       Timer
+}
+
+// SetTimeLimits enables time limits for the hand. It must be called before the hand begins.
+func (h *Hand) SetTimeLimits(tl TimeLimits) error {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	if h.isActive() {
+		return ErrHandActive
+	}
+	if err := tl.validate(); err != nil {
+		return err
+	}
+	h.setTimeLimits(tl)
+	return nil
+}
+
+func (tl TimeLimits) validate() error {
+	if tl.PerAction <= 0 {
+		return invalidRules("time limit per action must be positive")
+	}
+	if tl.TimeBank < 0 {
+		return invalidRules("time bank must not be negative")
+	}
+	return nil
+}
+
+func (h *Hand) setTimeLimits(tl TimeLimits) {
+	if tl.Clock == nil {
+		tl.Clock = systemClock{}
+	}
+	h.limits = &tl
+	h.banks = make(map[string]time.Duration)
+	for _, v := range h.players {
+		h.banks[v.Id] = tl.TimeBank
+	}
+}
+
+// TimeBank returns the time remaining in the player's time bank.
+func (h *Hand) TimeBank(playerId string) time.Duration {
+	h.m.RLock()
+	defer h.m.RUnlock()
+
+	return h.banks[playerId]
+}
+
+// startTurn starts timing the player who is next to play, stopping the timer of the previous turn.
+func (h *Hand) startTurn() {
+	if h.limits == nil {
+		return
+	}
+	h.timer.seq++
+	if h.timer.t != nil {
+		h.timer.t.Stop()
+		h.timer.t = nil
+	}
+	if h.isFinished() || h.nextToPlay == nil {
+		return
+	}
+	seq := h.timer.seq
+	h.timer.started = h.limits.Clock.Now()
+	h.timer.t = h.limits.Clock.AfterFunc(h.limits.PerAction+h.banks[h.nextToPlay.Id], func() {
+		h.expire(seq)
+	})
+}
+
+// endTurn draws down the time bank of the player who has just acted by any time taken over the
+// limit per action.
+func (h *Hand) endTurn(p *Player) {
+	if h.limits == nil {
+		return
+	}
+	over := h.limits.Clock.Now().Sub(h.timer.started) - h.limits.PerAction
+	if over <= 0 {
+		return
+	}
+	bank := h.banks[p.Id] - over
+	if bank < 0 {
+		bank = 0
+	}
+	h.banks[p.Id] = bank
+}
+
+func (h *Hand) expire(seq int) {
+	h.m.Lock()
+	if seq != h.timer.seq {
+		h.m.Unlock()
+		return
+	}
+	p := h.nextToPlay
+	inp := h.automaticInput(p, Check, Fold, Blind)
+	h.banks[p.Id] = 0
+	h.automatic = true
+	err := h.handleInput(p, inp)
+	h.automatic = false
+	if err != nil {
+		// the player's turn is timed again rather than left waiting without a clock
+		h.startTurn()
+		h.m.Unlock()
+		return
+	}
+	notify := h.limits.OnTimeout
+	h.m.Unlock()
+
+	if notify != nil {
+		notify(Timeout{HandId: h.Id, PlayerId: p.Id, Input: inp})
+	}
+}
@@ -14,3 +14,7 @@ func newTurnState(remaining []*Player) turn {
 	bs := newBettingStage(remaining, 4, curr, next)
 	return turn{bs}
 }
+
+func (curr turn) street() Street {
+	return Turn
+}
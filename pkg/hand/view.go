@@ -0,0 +1,97 @@
+package hand
+
+// View is an immutable snapshot of a hand as seen by one player. It includes the viewer's own hole
+// cards but hides those of opponents unless they were shown at showdown.
+type View struct {
+	HandId    string
+	Self      SeatView
+	Opponents []SeatView
+	Board     []Card
+	Pot       int
+	Street    Street
+	// Button is the seat of the dealer button, or 0 if the hand was not dealt at fixed seats.
+	Button int
+	// Moves are the valid moves of the viewer, if it is their turn.
+	Moves []Move
+}
+
+// SeatView is a player in the hand as seen by a viewer.
+type SeatView struct {
+	// Seat is the player's seat at the table, or 0 if the hand was not dealt at fixed seats.
+	Seat       int
+	Id         string
+	Name       string
+	Chips      int
+	Committed  int
+	Folded     bool
+	SittingOut bool
+	NextToPlay bool
+	// Cards are the player's hole cards if visible to the viewer, otherwise nil.
+	Cards []Card
+	// FaceDown is the number of hole cards the player holds which are hidden from the viewer.
+	FaceDown int
+}
+
+// ViewFor returns the hand as seen by the given player.
+func (h *Hand) ViewFor(playerId string) (View, error) {
+	h.m.RLock()
+	defer h.m.RUnlock()
+
+	return h.viewFor(playerId)
+}
+
+func (h *Hand) viewFor(playerId string) (View, error) {
+	var self *Player
+	for _, v := range h.dealt {
+		if v.Id == playerId {
+			self = v
+		}
+	}
+	if self == nil {
+		return View{}, ErrPlayerNotFound
+	}
+	v := h.view(func(p *Player) bool { return p == self || h.shown[p.Id] })
+	for i, o := range v.Opponents {
+		if o.Id == playerId {
+			v.Self = o
+			v.Opponents = append(v.Opponents[:i:i], v.Opponents[i+1:]...)
+			break
+		}
+	}
+	if h.isActive() {
+		v.Moves = append([]Move{}, h.stage.validMoves(h)[playerId]...)
+	}
+	return v, nil
+}
+
+// view returns the hand with every player dealt in as an opponent, revealing the hole cards of
+// those players for whom visible returns true.
+func (h *Hand) view(visible func(*Player) bool) View {
+	s := h.state()
+	v := View{
+		HandId: h.Id,
+		Board:  s.Board,
+		Pot:    s.Pot,
+		Street: s.Street,
+		Button: h.button,
+	}
+	for _, p := range h.dealt {
+		sv := SeatView{
+			Seat:       h.seats[p.Id],
+			Id:         p.Id,
+			Name:       p.Name,
+			Chips:      p.Chips,
+			Committed:  s.Committed[p.Id],
+			Folded:     p.Folded,
+			SittingOut: p.SittingOut,
+			NextToPlay: s.NextToPlay == p.Id,
+		}
+		if visible(p) {
+			sv.Cards = append([]Card{}, p.Cards...)
+		} else {
+			sv.FaceDown = len(p.Cards)
+		}
+		v.Opponents = append(v.Opponents, sv)
+	}
+	return v
+}
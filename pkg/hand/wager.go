@@ -0,0 +1,113 @@
+package hand
+
+// wager is the betting on a street as it stands for the player to act, from which the bets and
+// raises open to them are found. Hands and fast hands both find the moves of their players from it,
+// so that they are played by the same rules.
+type wager struct {
+	limit    BettingLimit
+	raises   RaiseUnit
+	bigBlind int
+	street   Street
+	// current is the highest stake on the street, and lastRaise the largest raise made on it.
+	current   int
+	lastRaise int
+	// committed is the chips the player has committed on the street, required those they must add
+	// to call and chips their stack.
+	committed int
+	required  int
+	chips     int
+	pot       int
+}
+
+// moves appends the moves open to the player to the slice and returns it.
+func (w wager) moves(mvs []Move) []Move {
+	mvs = append(mvs, NewMove(Fold, RequiredBet{}))
+	switch {
+	case w.required == 0 && w.current == 0:
+		mvs = append(mvs, NewMove(Check, RequiredBet{}))
+		// no bet may be made into an empty pot at pot limit
+		if r := w.betRange(); r.Minimum <= r.Maximum {
+			mvs = append(mvs, NewMove(Bet, r))
+		}
+	case w.required == 0:
+		mvs = append(mvs, NewMove(Check, RequiredBet{}))
+	case w.required < w.chips:
+		mvs = append(mvs, NewMove(Call, NewExactBet(w.required)))
+	default:
+		// calling all-in
+		mvs = append(mvs, NewMove(Call, NewExactBet(w.chips)))
+	}
+	if r := w.raiseRange(); w.current > 0 && r.Minimum <= r.Maximum {
+		mvs = append(mvs, NewMove(Raise, w.inRaiseUnit(r)))
+	}
+	return append(mvs, NewMove(AllIn, NewExactBet(w.chips)))
+}
+
+// raiseRange returns the smallest and largest totals to which the player may raise on this street.
+// If no bet has been made then the range is that of a bet. The minimum may exceed the maximum if
+// the player does not have the chips to raise other than by going all-in.
+func (w wager) raiseRange() RequiredBet {
+	if w.current == 0 {
+		return w.betRange()
+	}
+	size := w.lastRaise
+	if w.bigBlind > size {
+		size = w.bigBlind
+	}
+	if size == 0 {
+		size = 1
+	}
+	max := w.committed + w.chips
+	switch w.limit {
+	case FixedLimit:
+		size = w.fixedBet()
+		max = min(max, w.current+size)
+	case PotLimit:
+		max = min(max, w.current+w.pot+w.required)
+	}
+	return NewBetRange(w.current+size, max)
+}
+
+// betRange returns the smallest and largest bets the player may open with on this street. The
+// smallest bet is the big blind, if there is one, or their entire stack if it is smaller.
+func (w wager) betRange() RequiredBet {
+	least := w.bigBlind
+	if least == 0 {
+		least = 1
+	}
+	max := w.chips
+	switch w.limit {
+	case FixedLimit:
+		least = w.fixedBet()
+		max = min(max, least)
+	case PotLimit:
+		max = min(max, w.pot)
+	}
+	return NewBetRange(min(least, w.chips), max)
+}
+
+// fixedBet returns the size of bets and raises on this street in a fixed limit hand, which is the
+// big blind on the first betting round and twice it thereafter.
+func (w wager) fixedBet() int {
+	if w.street > Flop {
+		return 2 * w.bigBlind
+	}
+	return w.bigBlind
+}
+
+// inRaiseUnit converts a range of raise totals into the unit of raises in the rules.
+func (w wager) inRaiseUnit(to RequiredBet) RequiredBet {
+	if w.raises == RaiseTo {
+		return to
+	}
+	return NewBetRange(to.Minimum-w.committed, to.Maximum-w.committed)
+}
+
+// raiseTo returns the total to which raising the given chips raises the player's stake on the
+// street.
+func (w wager) raiseTo(chips int) int {
+	if w.raises == RaiseIncrement {
+		return w.committed + chips
+	}
+	return chips
+}
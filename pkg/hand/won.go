@@ -1,10 +1,5 @@
 package hand
 
-import (
-	"errors"
-	"sort"
-)
-
 type won struct {
 	ps []*Player
 }
@@ -17,39 +12,61 @@ func (curr won) requiredBet(h *Hand, p *Player) int {
 	return h.pot.required(*p)
 }
 
-type pHand struct {
-	player *Player
-	cards  []Card
-}
-
-type byHand []pHand
-
-func (p byHand) Len() int      { return len(p) }
-func (p byHand) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
-
-func (p byHand) Less(i, j int) bool { return false } // TODO: Sort by rank rather than deterministic sorting
-
 func (curr won) enter(h *Hand) error {
-	// evaluate hands
-	var pHands []pHand
+	total := h.pot.total()
+	if len(h.players) == 1 {
+		h.award(FinishedHand{winner: h.players[0], chips: total - h.rules.rake(total)})
+		return nil
+	}
+
+	for _, v := range h.players {
+		h.shown[v.Id] = true
+	}
 	for _, v := range h.players {
-		pH := pHand{v, append(h.Cards, v.Cards...)}
-		pHands = append(pHands, pH)
+		if h.evaluate(v, h.Cards) == 0 {
+			// hands whose cards are not known cannot be evaluated, so the first player remaining
+			// from the dealer takes the pot
+			h.award(FinishedHand{winner: h.players[0], chips: total - h.rules.rake(total)})
+			return nil
+		}
 	}
-	sort.Sort(byHand(pHands))
 
-	h.finish(FinishedHand{pHands[0].player, h.pot.total()})
+	sd := h.showdown(h.boards())
+	fh := FinishedHand{showdown: sd}
+	for _, v := range h.players {
+		if fh.winner == nil || sd.awards[v.Id] > sd.awards[fh.winner.Id] {
+			fh.winner = v
+		}
+		fh.chips += sd.awards[v.Id]
+	}
+	h.award(fh)
 	return nil
 }
 
+// award adds the chips won by each player to their stack and finishes the hand.
+func (h *Hand) award(fh FinishedHand) {
+	for _, v := range h.dealt {
+		v.Chips += fh.Awards()[v.Id]
+	}
+	h.finish(fh)
+}
+
 func (curr won) exit(h *Hand) error {
 	return nil
 }
 
 func (curr won) handleInput(h *Hand, p *Player, inp Input) (stage, error) {
-	return nil, errors.New("no action can be taken after winning")
+	return nil, ErrHandFinished
 }
 
 func (curr won) validMoves(h *Hand) map[string][]Move {
 	return make(map[string][]Move)
 }
+
+func (curr won) street() Street {
+	return Showdown
+}
+
+func (curr won) clone() stage {
+	return curr
+}
@@ -0,0 +1,283 @@
+// Package sim plays large numbers of hands between automated players to measure how they fare
+// against each other, in big blinds won per 100 hands.
+//
+// Every hand is dealt to players with the same stack, from a deck shuffled by a source seeded from
+// the simulation's seed, so a simulation is reproducible for players whose decisions are. The
+// players move round the seats every hand. When dealt in duplicate, each deal is played once with
+// the players in every seat, which cancels out much of the luck of the cards.
+package sim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/timothysugar/hand/pkg/hand"
+)
+
+// ErrNoBigBlind is returned when simulating hands without blinds, in which no big blind is won.
+var ErrNoBigBlind = errors.New("big blind required")
+
+// Decide returns the input played by an automated player given their view of the hand and their
+// valid moves. Blinds are posted for players so are never among the moves.
+type Decide func(v hand.View, moves []hand.Move) hand.Input
+
+// Decide makes the function a hand.Strategy.
+func (d Decide) Decide(v hand.View, moves []hand.Move) hand.Input {
+	return d(v, moves)
+}
+
+// Player is an automated player in a simulation.
+type Player struct {
+	Name string
+	// Decide must be safe for concurrent use if hands are played by more than one worker.
+	Decide Decide
+}
+
+// Config configures a simulation.
+type Config struct {
+	// Rules are the rules of every hand, which must have blinds. Rand is replaced by a source
+	// seeded for each deal.
+	Rules hand.Rules
+	// Stack is the chips each player starts every hand with.
+	Stack int
+	// Hands is the number of hands to play. In duplicate it is rounded up to play every deal in
+	// each seat.
+	Hands int
+	Seed  int64
+	// Duplicate plays each deal with the players in every seat.
+	Duplicate bool
+	// Workers is the number of hands played at once, or the number of CPUs if 0.
+	Workers int
+}
+
+// Result is how a player fared in a simulation.
+type Result struct {
+	Name  string
+	Hands int
+	// Chips is the total chips won, which is negative if chips were lost.
+	Chips int
+	// BBPer100 is the big blinds won per 100 hands.
+	BBPer100 float64
+	// CI95 is the half width of the 95% confidence interval of BBPer100.
+	CI95 float64
+	// Invalid is the number of decisions which were not valid moves, which were played as folds.
+	Invalid int
+}
+
+// Report is the result of a simulation.
+type Report struct {
+	Results  []Result
+	Hands    int
+	Duration time.Duration
+}
+
+// HandsPerSecond returns the rate at which hands were played.
+func (r Report) HandsPerSecond() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Hands) / r.Duration.Seconds()
+}
+
+// Run plays the hands of the simulation between the players, until they are played or the
+// context is done.
+func Run(ctx context.Context, players []Player, cfg Config) (Report, error) {
+	bb := 0
+	for _, v := range cfg.Rules.Blinds {
+		if v > bb {
+			bb = v
+		}
+	}
+	if bb == 0 {
+		return Report{}, ErrNoBigBlind
+	}
+	if len(players) < 2 {
+		return Report{}, hand.ErrNotEnoughPlayers
+	}
+	// each worker plays its hands with its own fast hand, whose deck is seeded for each deal
+	cfg.Rules.Rand = nil
+	if _, err := hand.NewFastHand(cfg.Rules, len(players)); err != nil {
+		return Report{}, err
+	}
+	if cfg.Stack <= 0 {
+		return Report{}, fmt.Errorf("%w: stack of %d", hand.ErrNoChips, cfg.Stack)
+	}
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	// the hands of a deal share their cards, and are measured together in duplicate
+	rotations := 1
+	if cfg.Duplicate {
+		rotations = len(players)
+	}
+	deals := (cfg.Hands + rotations - 1) / rotations
+
+	start := time.Now()
+	jobs := make(chan deal)
+	results := make(chan dealResult)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f, _ := hand.NewFastHand(cfg.Rules, len(players))
+			for d := range jobs {
+				results <- d.play(f, players, cfg)
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		r := rand.New(rand.NewSource(cfg.Seed))
+		for i := 0; i < deals; i++ {
+			d := deal{number: i, seed: r.Int63(), rotations: rotations}
+			if !cfg.Duplicate {
+				d.first = i % len(players)
+			}
+			select {
+			case jobs <- d:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	stats := make([]stat, len(players))
+	var err error
+	played := 0
+	for res := range results {
+		if res.err != nil {
+			if err == nil {
+				err = res.err
+			}
+			continue
+		}
+		played += res.hands
+		for i := range stats {
+			stats[i].add(res, i)
+		}
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+	report := Report{Hands: played, Duration: time.Since(start)}
+	for i, p := range players {
+		report.Results = append(report.Results, stats[i].result(p.Name, bb))
+	}
+	return report, err
+}
+
+// deal is a shuffle of the deck to be played with the players moved round the seats a number of
+// times, starting with the player in the first seat.
+type deal struct {
+	number    int
+	seed      int64
+	first     int
+	rotations int
+}
+
+type dealResult struct {
+	hands int
+	// chips, played and invalid are by player.
+	chips   []int
+	played  []int
+	invalid []int
+	err     error
+}
+
+func (d deal) play(f *hand.FastHand, players []Player, cfg Config) dealResult {
+	n := len(players)
+	res := dealResult{chips: make([]int, n), played: make([]int, n), invalid: make([]int, n)}
+	seated := make([]int, n)
+	for k := 0; k < d.rotations; k++ {
+		for seat := range seated {
+			seated[seat] = (seat + d.first + k) % n
+		}
+		if err := playHand(f, players, seated, d.seed, cfg, &res); err != nil {
+			res.err = fmt.Errorf("deal %d: %w", d.number, err)
+			return res
+		}
+		res.hands++
+	}
+	return res
+}
+
+// playHand plays a hand with the players in the seats and adds their winnings to the result.
+func playHand(f *hand.FastHand, players []Player, seated []int, seed int64, cfg Config, res *dealResult) error {
+	stacks := make([]int, len(seated))
+	bots := make([]hand.Strategy, len(seated))
+	for seat, i := range seated {
+		stacks[seat] = cfg.Stack
+		bots[seat] = players[i].Decide
+	}
+	f.Seed(seed)
+	won, err := f.Play(stacks, bots)
+	if err != nil {
+		return err
+	}
+	for seat, i := range seated {
+		res.chips[i] += won[seat]
+		res.invalid[i] += f.Invalid(seat)
+		res.played[i]++
+	}
+	return nil
+}
+
+// stat accumulates the winnings of a player over samples, which are single hands or the average
+// of the hands of a deal in duplicate.
+type stat struct {
+	hands, chips, invalid int
+	samples               int
+	sum, sumSquares       float64
+}
+
+func (s *stat) add(res dealResult, i int) {
+	if res.played[i] == 0 {
+		return
+	}
+	s.hands += res.played[i]
+	s.chips += res.chips[i]
+	s.invalid += res.invalid[i]
+	x := float64(res.chips[i]) / float64(res.played[i])
+	s.samples++
+	s.sum += x
+	s.sumSquares += x * x
+}
+
+func (s stat) result(name string, bb int) Result {
+	r := Result{Name: name, Hands: s.hands, Chips: s.chips, Invalid: s.invalid}
+	if s.samples == 0 {
+		return r
+	}
+	n := float64(s.samples)
+	mean := s.sum / n
+	r.BBPer100 = 100 * mean / float64(bb)
+	if s.samples > 1 {
+		variance := (s.sumSquares - n*mean*mean) / (n - 1)
+		r.CI95 = 1.96 * 100 * math.Sqrt(math.Max(variance, 0)/n) / float64(bb)
+	}
+	return r
+}
+
+// Random returns a decision which plays a valid move chosen at random, seeded for reproducibility.
+// It is safe for concurrent use, though the moves of concurrent hands depend on their timing.
+func Random(seed int64) Decide {
+	var m sync.Mutex
+	s := hand.NewRandomStrategy(rand.New(rand.NewSource(seed)))
+	return func(v hand.View, moves []hand.Move) hand.Input {
+		m.Lock()
+		defer m.Unlock()
+		return s.Decide(v, moves)
+	}
+}
@@ -0,0 +1,86 @@
+package sim
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/timothysugar/hand/pkg/hand"
+)
+
+var rules = hand.Rules{Blinds: []int{1, 2}, PreflopBetting: true}
+
+func TestRunInDuplicateCancelsLuckOfIdenticalPlayers(t *testing.T) {
+	players := []Player{
+		{Name: "one", Decide: hand.CallingStation{}.Decide},
+		{Name: "two", Decide: hand.CallingStation{}.Decide},
+	}
+
+	got, err := Run(context.Background(), players, Config{Rules: rules, Stack: 200, Hands: 1000, Seed: 1, Duplicate: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Hands != 1000 {
+		t.Errorf("expected 1000 hands but got %d", got.Hands)
+	}
+	for _, r := range got.Results {
+		if r.Hands != 1000 || r.Chips != 0 || r.BBPer100 != 0 || r.CI95 != 0 {
+			t.Errorf("expected every deal to be broken even in duplicate but got %+v", r)
+		}
+	}
+}
+
+func TestRunIsReproducibleWithSeed(t *testing.T) {
+	players := []Player{
+		{Name: "tag", Decide: hand.TightAggressive{}.Decide},
+		{Name: "random", Decide: Random(3)},
+		{Name: "call", Decide: hand.CallingStation{}.Decide},
+	}
+	cfg := Config{Rules: rules, Stack: 200, Hands: 300, Seed: 7, Workers: 1}
+
+	first, err := Run(context.Background(), players, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	players[1].Decide = Random(3)
+	second, _ := Run(context.Background(), players, cfg)
+
+	total := 0
+	for i, r := range first.Results {
+		total += r.Chips
+		if r.Hands != 300 || r.CI95 <= 0 {
+			t.Errorf("expected %s to play every hand with an interval but got %+v", r.Name, r)
+		}
+		if r != second.Results[i] {
+			t.Errorf("expected the same result with the same seed but got %+v and %+v", r, second.Results[i])
+		}
+	}
+	if total != 0 {
+		t.Errorf("expected chips won to equal chips lost but got %d", total)
+	}
+}
+
+func TestRunFoldsInvalidDecisions(t *testing.T) {
+	players := []Player{
+		{Name: "broken", Decide: func(hand.View, []hand.Move) hand.Input { return hand.Input{Action: hand.Raise} }},
+		{Name: "call", Decide: hand.CallingStation{}.Decide},
+	}
+
+	got, err := Run(context.Background(), players, Config{Rules: rules, Stack: 200, Hands: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Results[0].Invalid != 10 || got.Results[0].Chips >= 0 {
+		t.Errorf("expected every invalid raise to fold but got %+v", got.Results[0])
+	}
+}
+
+func TestRunRequiresBigBlind(t *testing.T) {
+	players := []Player{{Name: "one"}, {Name: "two"}}
+
+	if _, err := Run(context.Background(), players, Config{Stack: 200, Hands: 1}); !errors.Is(err, ErrNoBigBlind) {
+		t.Errorf("expected %v but got %v", ErrNoBigBlind, err)
+	}
+}